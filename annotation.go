@@ -0,0 +1,125 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrAnnotationNotFound is the error msg for a missing annotation.
+const ErrAnnotationNotFound = "annotation not found"
+
+// ops for annotation service.
+const (
+	OpFindAnnotationByID = "FindAnnotationByID"
+	OpFindAnnotations    = "FindAnnotations"
+	OpCreateAnnotation   = "CreateAnnotation"
+	OpDeleteAnnotation   = "DeleteAnnotation"
+)
+
+// AnnotationService describes a service for managing annotations.
+type AnnotationService interface {
+	// FindAnnotationByID returns a single annotation by ID.
+	FindAnnotationByID(ctx context.Context, id ID) (*Annotation, error)
+
+	// FindAnnotations returns the annotations of an organization matching filter,
+	// ordered by start time.
+	FindAnnotations(ctx context.Context, filter AnnotationFilter) ([]*Annotation, error)
+
+	// CreateAnnotation creates a new annotation and sets a.ID with the new identifier.
+	CreateAnnotation(ctx context.Context, a *Annotation) error
+
+	// DeleteAnnotation removes an annotation by ID.
+	DeleteAnnotation(ctx context.Context, id ID) error
+}
+
+// Annotation marks a time range of an organization, such as a deploy or an
+// incident, so that it can be overlaid on the graphs of dashboards.
+// An annotation without a dashboard ID applies to every dashboard of its organization.
+type Annotation struct {
+	ID             ID                `json:"id,omitempty"`
+	OrganizationID ID                `json:"orgID,omitempty"`
+	DashboardID    *ID               `json:"dashboardID,omitempty"`
+	Summary        string            `json:"summary"`
+	Message        string            `json:"message,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	StartTime      time.Time         `json:"startTime"`
+	EndTime        time.Time         `json:"endTime"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+// Valid returns an error if the annotation is missing required fields or
+// its time range is inverted.
+func (a *Annotation) Valid() error {
+	if !a.OrganizationID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "annotation requires an organization",
+		}
+	}
+	if a.Summary == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "annotation requires a summary",
+		}
+	}
+	if a.StartTime.IsZero() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "annotation requires a start time",
+		}
+	}
+	if a.EndTime.Before(a.StartTime) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "annotation end time must not be before its start time",
+		}
+	}
+	return nil
+}
+
+// Overlaps reports whether the annotation's time range overlaps [start, end].
+// A zero start or end leaves that side of the range unbounded.
+func (a *Annotation) Overlaps(start, end time.Time) bool {
+	if !end.IsZero() && a.StartTime.After(end) {
+		return false
+	}
+	if !start.IsZero() && a.EndTime.Before(start) {
+		return false
+	}
+	return true
+}
+
+// AnnotationFilter is a filter for the annotations of an organization.
+type AnnotationFilter struct {
+	OrganizationID ID
+
+	// DashboardID restricts the annotations to those of a dashboard,
+	// including the ones applying to every dashboard of the organization.
+	DashboardID *ID
+
+	// Start and End restrict the annotations to those overlapping the time range.
+	Start time.Time
+	End   time.Time
+
+	// Tags restricts the annotations to those having all of the tags.
+	Tags map[string]string
+}
+
+// Match reports whether the annotation matches the filter.
+func (f AnnotationFilter) Match(a *Annotation) bool {
+	if a.OrganizationID != f.OrganizationID {
+		return false
+	}
+	if f.DashboardID != nil && a.DashboardID != nil && *a.DashboardID != *f.DashboardID {
+		return false
+	}
+	if !a.Overlaps(f.Start, f.End) {
+		return false
+	}
+	for k, v := range f.Tags {
+		if tv, ok := a.Tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+	return true
+}
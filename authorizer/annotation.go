@@ -0,0 +1,80 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AnnotationService = (*AnnotationService)(nil)
+
+// AnnotationService wraps a influxdb.AnnotationService and authorizes actions
+// against it appropriately.
+//
+// Annotations are overlaid on dashboards, so they are authorized against
+// the dashboards of their organization.
+type AnnotationService struct {
+	s influxdb.AnnotationService
+}
+
+// NewAnnotationService constructs an instance of an authorizing annotation service.
+func NewAnnotationService(s influxdb.AnnotationService) *AnnotationService {
+	return &AnnotationService{
+		s: s,
+	}
+}
+
+func authorizeAnnotation(ctx context.Context, a influxdb.Action, orgID influxdb.ID) error {
+	p, err := influxdb.NewPermission(a, influxdb.DashboardsResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	return IsAllowed(ctx, *p)
+}
+
+// FindAnnotationByID checks to see if the authorizer on context has read access to the dashboards of the annotation's organization.
+func (s *AnnotationService) FindAnnotationByID(ctx context.Context, id influxdb.ID) (*influxdb.Annotation, error) {
+	a, err := s.s.FindAnnotationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeAnnotation(ctx, influxdb.ReadAction, a.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FindAnnotations checks to see if the authorizer on context has read access to the dashboards of the filter's organization.
+func (s *AnnotationService) FindAnnotations(ctx context.Context, filter influxdb.AnnotationFilter) ([]*influxdb.Annotation, error) {
+	if err := authorizeAnnotation(ctx, influxdb.ReadAction, filter.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindAnnotations(ctx, filter)
+}
+
+// CreateAnnotation checks to see if the authorizer on context has write access to the dashboards of the annotation's organization.
+func (s *AnnotationService) CreateAnnotation(ctx context.Context, a *influxdb.Annotation) error {
+	if err := authorizeAnnotation(ctx, influxdb.WriteAction, a.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.CreateAnnotation(ctx, a)
+}
+
+// DeleteAnnotation checks to see if the authorizer on context has write access to the dashboards of the annotation's organization.
+func (s *AnnotationService) DeleteAnnotation(ctx context.Context, id influxdb.ID) error {
+	a, err := s.s.FindAnnotationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeAnnotation(ctx, influxdb.WriteAction, a.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteAnnotation(ctx, id)
+}
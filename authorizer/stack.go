@@ -0,0 +1,109 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.StackService = (*StackService)(nil)
+
+// StackService wraps a influxdb.StackService and authorizes actions
+// against it appropriately.
+//
+// Stacks are not a resource of their own; they are authorized against the
+// dashboards of their organization, the resources templates are made of.
+type StackService struct {
+	s influxdb.StackService
+}
+
+// NewStackService constructs an instance of an authorizing stack service.
+func NewStackService(s influxdb.StackService) *StackService {
+	return &StackService{
+		s: s,
+	}
+}
+
+func authorizeStack(ctx context.Context, a influxdb.Action, orgID influxdb.ID) error {
+	p, err := influxdb.NewPermission(a, influxdb.DashboardsResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	return IsAllowed(ctx, *p)
+}
+
+// FindStackByID checks to see if the authorizer on context has read access to the dashboards of the stack's organization.
+func (s *StackService) FindStackByID(ctx context.Context, id influxdb.ID) (*influxdb.Stack, error) {
+	st, err := s.s.FindStackByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeStack(ctx, influxdb.ReadAction, st.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// FindStacks retrieves all stacks that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *StackService) FindStacks(ctx context.Context, filter influxdb.StackFilter) ([]*influxdb.Stack, error) {
+	sts, err := s.s.FindStacks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stacks := sts[:0]
+	for _, st := range sts {
+		err := authorizeStack(ctx, influxdb.ReadAction, st.OrganizationID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		stacks = append(stacks, st)
+	}
+
+	return stacks, nil
+}
+
+// CreateStack checks to see if the authorizer on context has write access to the dashboards of the stack's organization.
+func (s *StackService) CreateStack(ctx context.Context, st *influxdb.Stack) error {
+	if err := authorizeStack(ctx, influxdb.WriteAction, st.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.CreateStack(ctx, st)
+}
+
+// ReplaceStack checks to see if the authorizer on context has write access to the dashboards of the stack's organization.
+func (s *StackService) ReplaceStack(ctx context.Context, st *influxdb.Stack) error {
+	prev, err := s.s.FindStackByID(ctx, st.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeStack(ctx, influxdb.WriteAction, prev.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.ReplaceStack(ctx, st)
+}
+
+// DeleteStack checks to see if the authorizer on context has write access to the dashboards of the stack's organization.
+func (s *StackService) DeleteStack(ctx context.Context, id influxdb.ID) error {
+	st, err := s.s.FindStackByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeStack(ctx, influxdb.WriteAction, st.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteStack(ctx, id)
+}
@@ -0,0 +1,381 @@
+// Package badger provides a kv.Store backed by the badger LSM tree.
+//
+// Badger has no notion of buckets, so every key is stored with a prefix
+// identifying the bucket it belongs to:
+//
+//	<len(bucket) as big-endian uint16><bucket><key> -> value
+//
+// Cursors do not hold badger iterators open between calls, since badger only
+// allows a single open iterator per transaction; every movement of a cursor
+// seeks a short-lived iterator from the cursor's last position instead.
+package badger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"go.uber.org/zap"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/kv"
+)
+
+// DefaultGCInterval is how often the value log garbage collection runs.
+const DefaultGCInterval = 5 * time.Minute
+
+// gcDiscardRatio is the fraction of a value log file that must be discardable
+// before the file is rewritten.
+const gcDiscardRatio = 0.5
+
+// KVStore is a kv.Store backed by badger.
+type KVStore struct {
+	path   string
+	db     *badger.DB
+	logger *zap.Logger
+
+	// GCInterval is how often the value log is garbage collected.
+	// It must be set before the store is opened.
+	GCInterval time.Duration
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewKVStore returns an instance of KVStore with its files in the
+// directory at the provided path.
+func NewKVStore(path string) *KVStore {
+	return &KVStore{
+		path:       path,
+		logger:     zap.NewNop(),
+		GCInterval: DefaultGCInterval,
+	}
+}
+
+// Open creates the badger directory if it doesn't exist and opens the database.
+func (s *KVStore) Open(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := os.MkdirAll(s.path, 0700); err != nil {
+		return fmt.Errorf("unable to create directory %s: %v", s.path, err)
+	}
+
+	opts := badger.DefaultOptions
+	opts.Dir = s.path
+	opts.ValueDir = s.path
+	db, err := badger.Open(opts)
+	if err != nil {
+		return fmt.Errorf("unable to open badger database %v", err)
+	}
+	s.db = db
+
+	s.closing = make(chan struct{})
+	if s.GCInterval > 0 {
+		s.wg.Add(1)
+		go s.runGC(s.GCInterval)
+	}
+
+	s.logger.Info("Resources opened", zap.String("path", s.path))
+	return nil
+}
+
+// runGC periodically rewrites value log files with a large proportion of stale entries.
+func (s *KVStore) runGC(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+			// RunValueLogGC rewrites at most one file per call.
+			for {
+				if err := s.db.RunValueLogGC(gcDiscardRatio); err != nil {
+					if err != badger.ErrNoRewrite {
+						s.logger.Info("Value log garbage collection failed", zap.Error(err))
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// Close the connection to the badger database.
+func (s *KVStore) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	close(s.closing)
+	s.wg.Wait()
+	return s.db.Close()
+}
+
+// Flush removes all keys from the store.
+func (s *KVStore) Flush(ctx context.Context) {
+	var keys [][]byte
+	_ = s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+
+	// Delete in batches so that no single transaction grows too big.
+	const batchSize = 1000
+	for len(keys) > 0 {
+		n := batchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		_ = s.db.Update(func(txn *badger.Txn) error {
+			for _, k := range keys[:n] {
+				if err := txn.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		keys = keys[n:]
+	}
+}
+
+// WithLogger sets the logger on the store.
+func (s *KVStore) WithLogger(l *zap.Logger) {
+	s.logger = l
+}
+
+// Backup writes a snapshot of every key in the database to w.
+// Writes to the store may continue while the snapshot is being taken.
+func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	_, err := s.db.Backup(w, 0)
+	return err
+}
+
+// View opens up a view transaction against the store.
+func (s *KVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.db.View(func(txn *badger.Txn) error {
+		return fn(&Tx{
+			txn: txn,
+			ctx: ctx,
+		})
+	})
+}
+
+// Update opens up an update transaction against the store.
+func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return fn(&Tx{
+			txn: txn,
+			ctx: ctx,
+		})
+	})
+}
+
+// Tx is a light wrapper around a badger transaction. It implements kv.Tx.
+type Tx struct {
+	txn *badger.Txn
+	ctx context.Context
+}
+
+// Context returns the context for the transaction.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// WithContext sets the context for the transaction.
+func (tx *Tx) WithContext(ctx context.Context) {
+	tx.ctx = ctx
+}
+
+// Bucket retrieves the bucket named b.
+// Buckets only exist as key prefixes in badger, so they never need to be created.
+func (tx *Tx) Bucket(b []byte) (kv.Bucket, error) {
+	if len(b) > math.MaxUint16 {
+		return nil, fmt.Errorf("bucket name too long: %d bytes", len(b))
+	}
+	prefix := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(prefix, uint16(len(b)))
+	copy(prefix[2:], b)
+	return &Bucket{
+		txn:    tx.txn,
+		prefix: prefix,
+	}, nil
+}
+
+// Bucket implements kv.Bucket.
+type Bucket struct {
+	txn    *badger.Txn
+	prefix []byte
+}
+
+func (b *Bucket) key(k []byte) []byte {
+	key := make([]byte, len(b.prefix)+len(k))
+	copy(key, b.prefix)
+	copy(key[len(b.prefix):], k)
+	return key
+}
+
+// Get retrieves the value at the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	item, err := b.txn.Get(b.key(key))
+	if err == badger.ErrKeyNotFound {
+		return nil, kv.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// Put sets the value at the provided key.
+func (b *Bucket) Put(key []byte, value []byte) error {
+	err := b.txn.Set(b.key(key), value)
+	if err == badger.ErrReadOnlyTxn {
+		return kv.ErrTxNotWritable
+	}
+	return err
+}
+
+// Delete removes the provided key.
+func (b *Bucket) Delete(key []byte) error {
+	err := b.txn.Delete(b.key(key))
+	if err == badger.ErrReadOnlyTxn {
+		return kv.ErrTxNotWritable
+	}
+	return err
+}
+
+// Cursor retrieves a cursor for iterating through the entries
+// in the key value store.
+func (b *Bucket) Cursor() (kv.Cursor, error) {
+	return &Cursor{
+		txn:    b.txn,
+		prefix: b.prefix,
+	}, nil
+}
+
+// Cursor is a struct for iterating through the entries
+// in the key value store.
+type Cursor struct {
+	txn    *badger.Txn
+	prefix []byte
+
+	// key is the full key, including the bucket prefix, of the cursor's current position.
+	// It is nil when the cursor is not positioned on a key.
+	key []byte
+}
+
+// Seek seeks for the first key that matches the prefix provided.
+func (c *Cursor) Seek(prefix []byte) ([]byte, []byte) {
+	seek := make([]byte, len(c.prefix)+len(prefix))
+	copy(seek, c.prefix)
+	copy(seek[len(c.prefix):], prefix)
+	return c.seek(seek, false, nil)
+}
+
+// First retrieves the first key value pair in the bucket.
+func (c *Cursor) First() ([]byte, []byte) {
+	return c.seek(c.prefix, false, nil)
+}
+
+// Last retrieves the last key value pair in the bucket.
+func (c *Cursor) Last() ([]byte, []byte) {
+	upper := prefixUpperBound(c.prefix)
+	if upper == nil {
+		// Every byte of the prefix is 0xff; no key can sort after the bucket.
+		return c.seek(nil, true, nil)
+	}
+	return c.seek(upper, true, upper)
+}
+
+// Next retrieves the next key in the bucket.
+func (c *Cursor) Next() ([]byte, []byte) {
+	if c.key == nil {
+		return nil, nil
+	}
+	// The smallest key sorting after the current key.
+	next := make([]byte, len(c.key)+1)
+	copy(next, c.key)
+	return c.seek(next, false, nil)
+}
+
+// Prev retrieves the previous key in the bucket.
+func (c *Cursor) Prev() ([]byte, []byte) {
+	if c.key == nil {
+		return nil, nil
+	}
+	return c.seek(c.key, true, c.key)
+}
+
+// seek positions the cursor on the first key at or after key, or, when reverse
+// is set, on the last key at or before key. If the key found equals skip, the
+// iterator moves one more step. The cursor is left unpositioned when no key
+// in the bucket is found.
+func (c *Cursor) seek(key []byte, reverse bool, skip []byte) ([]byte, []byte) {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Reverse = reverse
+	it := c.txn.NewIterator(opts)
+	defer it.Close()
+
+	if key == nil {
+		it.Rewind()
+	} else {
+		it.Seek(key)
+	}
+	if skip != nil && it.Valid() && bytes.Equal(it.Item().Key(), skip) {
+		it.Next()
+	}
+
+	if !it.ValidForPrefix(c.prefix) {
+		c.key = nil
+		return nil, nil
+	}
+
+	item := it.Item()
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		c.key = nil
+		return nil, nil
+	}
+	c.key = item.KeyCopy(nil)
+	return c.key[len(c.prefix):], v
+}
+
+// prefixUpperBound returns the smallest key that sorts after every key starting with prefix,
+// or nil if there is no such key.
+func prefixUpperBound(prefix []byte) []byte {
+	upper := make([]byte, len(prefix))
+	copy(upper, prefix)
+	for i := len(upper) - 1; i >= 0; i-- {
+		if upper[i] < 0xff {
+			upper[i]++
+			return upper[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package badger_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/badger"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func NewTestKVStore() (*badger.KVStore, func(), error) {
+	path, err := ioutil.TempDir("", "influxdata-platform-badger-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := badger.NewKVStore(path)
+	if err := s.Open(context.Background()); err != nil {
+		os.RemoveAll(path)
+		return nil, nil, err
+	}
+
+	close := func() {
+		s.Close()
+		os.RemoveAll(path)
+	}
+
+	return s, close, nil
+}
+
+func initKVStore(f platformtesting.KVStoreFields, t *testing.T) (kv.Store, func()) {
+	s, closeFn, err := NewTestKVStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	err = s.Update(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket(f.Bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range f.Pairs {
+			if err := b.Put(p.Key, p.Value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to put keys: %v", err)
+	}
+	return s, func() {
+		closeFn()
+	}
+}
+
+func TestKVStore(t *testing.T) {
+	platformtesting.KVStore(initKVStore, t)
+}
+
+func TestKVStore_BucketIsolation(t *testing.T) {
+	s, closeFn, err := NewTestKVStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFn()
+
+	// "a" followed by a key in bucket "a" must not be visible from bucket "ab", and vice versa.
+	if err := s.Update(context.Background(), func(tx kv.Tx) error {
+		for _, name := range []string{"a", "ab", "b"} {
+			b, err := tx.Bucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			for _, k := range []string{"1", "2", "3"} {
+				if err := b.Put([]byte(k), []byte(name+k)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.View(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("ab"))
+		if err != nil {
+			return err
+		}
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+
+		var got []string
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			got = append(got, string(k)+"="+string(v))
+		}
+		if exp := []string{"1=ab1", "2=ab2", "3=ab3"}; !equalStrings(got, exp) {
+			t.Errorf("forward iteration: got %v, exp %v", got, exp)
+		}
+
+		got = got[:0]
+		for k, v := cur.Last(); k != nil; k, v = cur.Prev() {
+			got = append(got, string(k)+"="+string(v))
+		}
+		if exp := []string{"3=ab3", "2=ab2", "1=ab1"}; !equalStrings(got, exp) {
+			t.Errorf("reverse iteration: got %v, exp %v", got, exp)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
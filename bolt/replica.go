@@ -0,0 +1,234 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/coreos/bbolt"
+	"go.uber.org/zap"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/kv"
+)
+
+// DefaultReplicaRefreshInterval is how often a replica fetches a new snapshot from its primary.
+const DefaultReplicaRefreshInterval = time.Minute
+
+// ErrReadReplica is returned when attempting to modify a read replica.
+var ErrReadReplica = &influxdb.Error{
+	Code: influxdb.EMethodNotAllowed,
+	Msg:  "the metadata store is a read-only replica",
+}
+
+// Snapshotter writes a consistent snapshot of a bolt database to w.
+type Snapshotter interface {
+	Backup(ctx context.Context, w io.Writer) error
+}
+
+// ReplicaKVStore is a read-only kv.Store serving a local copy of a primary's bolt database.
+// The local copy is replaced by a fresh snapshot of the primary every RefreshInterval.
+type ReplicaKVStore struct {
+	path    string
+	primary Snapshotter
+	logger  *zap.Logger
+
+	// RefreshInterval is how often a new snapshot is fetched from the primary.
+	// It must be set before the store is opened.
+	RefreshInterval time.Duration
+
+	mu        sync.RWMutex
+	db        *bolt.DB
+	refreshed time.Time
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewReplicaKVStore returns a ReplicaKVStore keeping its copy of primary in the file at path.
+func NewReplicaKVStore(path string, primary Snapshotter) *ReplicaKVStore {
+	return &ReplicaKVStore{
+		path:            path,
+		primary:         primary,
+		logger:          zap.NewNop(),
+		RefreshInterval: DefaultReplicaRefreshInterval,
+	}
+}
+
+// WithLogger sets the logger on the store.
+func (s *ReplicaKVStore) WithLogger(l *zap.Logger) {
+	s.logger = l
+}
+
+// Open fetches an initial snapshot from the primary and starts refreshing it periodically.
+// If the primary is unreachable, the replica falls back to the last snapshot stored at its path.
+func (s *ReplicaKVStore) Open(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("unable to create directory %s: %v", s.path, err)
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		if _, statErr := os.Stat(s.path); statErr != nil {
+			return fmt.Errorf("unable to fetch snapshot from primary: %v", err)
+		}
+		s.logger.Info("Failed to fetch snapshot from primary; serving previous snapshot", zap.Error(err))
+		if err := s.swap(s.path); err != nil {
+			return err
+		}
+	}
+
+	s.closing = make(chan struct{})
+	if s.RefreshInterval > 0 {
+		s.wg.Add(1)
+		go s.run(s.RefreshInterval)
+	}
+
+	s.logger.Info("Resources opened", zap.String("path", s.path))
+	return nil
+}
+
+func (s *ReplicaKVStore) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+			if err := s.Refresh(context.Background()); err != nil {
+				s.logger.Info("Failed to refresh snapshot from primary", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Refresh fetches a new snapshot from the primary and starts serving it.
+// Transactions in progress finish against the previous snapshot.
+func (s *ReplicaKVStore) Refresh(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := s.primary.Backup(ctx, f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Ensure the snapshot is a valid bolt file before replacing the current one.
+	db, err := bolt.Open(tmp, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("invalid snapshot from primary: %v", err)
+	}
+	db.Close()
+
+	return s.swap(tmp)
+}
+
+// swap closes the current database, moves the file at src to the store's path and opens it.
+func (s *ReplicaKVStore) swap(src string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		if err := s.db.Close(); err != nil {
+			return err
+		}
+		s.db = nil
+	}
+
+	if src != s.path {
+		if err := os.Rename(src, s.path); err != nil {
+			return err
+		}
+	}
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("unable to open boltdb file %v", err)
+	}
+	s.db = db
+	s.refreshed = time.Now()
+	return nil
+}
+
+// LastRefresh returns the time the snapshot currently being served was loaded.
+func (s *ReplicaKVStore) LastRefresh() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.refreshed
+}
+
+// Close stops refreshing and closes the local copy of the database.
+func (s *ReplicaKVStore) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+		s.wg.Wait()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil {
+		err := s.db.Close()
+		s.db = nil
+		return err
+	}
+	return nil
+}
+
+// Backup writes the snapshot currently being served to w, so replicas may be chained.
+func (s *ReplicaKVStore) Backup(ctx context.Context, w io.Writer) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// View opens up a view transaction against the most recent snapshot.
+func (s *ReplicaKVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&Tx{
+			tx:  tx,
+			ctx: ctx,
+		})
+	})
+}
+
+// Update always returns ErrReadReplica; replicas can only be modified through their primary.
+func (s *ReplicaKVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
+	return ErrReadReplica
+}
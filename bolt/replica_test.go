@@ -0,0 +1,96 @@
+package bolt_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestReplicaKVStore(t *testing.T) {
+	primary, closePrimary, err := NewTestKVStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closePrimary()
+
+	dir, err := ioutil.TempDir("", "influxdata-platform-bolt-replica-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx    = context.Background()
+		bucket = []byte("bucket")
+	)
+
+	put := func(key, value string) {
+		t.Helper()
+		if err := primary.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket(bucket)
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(key), []byte(value))
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(s kv.Store, key string) string {
+		t.Helper()
+		var v []byte
+		if err := s.View(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket(bucket)
+			if err != nil {
+				return err
+			}
+			v, err = b.Get([]byte(key))
+			if kv.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return string(v)
+	}
+
+	put("a", "1")
+
+	replica := bolt.NewReplicaKVStore(filepath.Join(dir, "replica.bolt"), primary)
+	replica.RefreshInterval = 0
+	if err := replica.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	if v := get(replica, "a"); v != "1" {
+		t.Fatalf("expected replica to contain a=1, got %q", v)
+	}
+
+	if err := replica.Update(ctx, func(kv.Tx) error { return nil }); err != bolt.ErrReadReplica {
+		t.Fatalf("expected ErrReadReplica, got %v", err)
+	}
+
+	put("a", "2")
+	put("b", "3")
+	if v := get(replica, "b"); v != "" {
+		t.Fatalf("expected replica not to see writes before refresh, got b=%q", v)
+	}
+
+	if err := replica.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v := get(replica, "a"); v != "2" {
+		t.Fatalf("expected replica to contain a=2 after refresh, got %q", v)
+	}
+	if v := get(replica, "b"); v != "3" {
+		t.Fatalf("expected replica to contain b=3 after refresh, got %q", v)
+	}
+}
@@ -67,6 +67,7 @@ in the following ways:
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
 
 	base.AddCommand(reportTSMCommand)
+	base.AddCommand(newKVCommand())
 	return base
 }
 
@@ -0,0 +1,287 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/spf13/cobra"
+)
+
+// taskMetaBucket is the name of the bucket in which the bolt task store keeps
+// protobuf encoded backend.StoreTaskMeta values.
+var taskMetaBucket = []byte("/tasks/v1/task_meta")
+
+// inspectKVFlags defines the flags shared by the `kv` subcommands.
+var inspectKVFlags = struct {
+	boltPath string
+	buckets  []string
+	limit    int
+	prefix   string
+	raw      bool
+}{}
+
+func newKVCommand() *cobra.Command {
+	kvCommand := &cobra.Command{
+		Use:   "kv",
+		Short: "Commands for inspecting an offline bolt metadata file",
+		Long: `
+These commands open the bolt file used by influxd to store its metadata in
+read-only mode. The file must not be in use by a running influxd process.
+
+Nested buckets are addressed by repeating the --bucket flag, from the outermost
+bucket to the innermost one.`,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	path := filepath.Join(dir, "influxd.bolt")
+	kvCommand.PersistentFlags().StringVarP(&inspectKVFlags.boltPath, "bolt-path", "", path, fmt.Sprintf("path to boltdb database (defaults to %s).", path))
+	kvCommand.PersistentFlags().StringArrayVarP(&inspectKVFlags.buckets, "bucket", "", nil, "bucket to inspect; repeat the flag to address nested buckets")
+
+	bucketsCommand := &cobra.Command{
+		Use:   "buckets",
+		Short: "List buckets and their nested buckets",
+		RunE:  inspectKVBucketsF,
+	}
+
+	dumpCommand := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the keys and values of a bucket",
+		Long: `
+Dump prints every key and value stored in the bucket selected with --bucket.
+
+Values are printed as-is when they are JSON or printable text, and hex encoded
+otherwise. Values of the task store's task_meta bucket are decoded from their
+protocol buffer encoding and printed as JSON, unless --raw is set.`,
+		RunE: inspectKVDumpF,
+	}
+	dumpCommand.Flags().IntVarP(&inspectKVFlags.limit, "limit", "", 0, "maximum number of keys to print; zero means no limit")
+	dumpCommand.Flags().StringVarP(&inspectKVFlags.prefix, "prefix", "", "", "only print keys starting with prefix")
+	dumpCommand.Flags().BoolVarP(&inspectKVFlags.raw, "raw", "", false, "do not decode values; print them hex encoded")
+
+	statsCommand := &cobra.Command{
+		Use:   "stats",
+		Short: "Report key counts and sizes of buckets",
+		RunE:  inspectKVStatsF,
+	}
+
+	kvCommand.AddCommand(bucketsCommand, dumpCommand, statsCommand)
+	return kvCommand
+}
+
+// openBoltReadOnly opens the bolt file at path without taking the write lock.
+func openBoltReadOnly(path string) (*bolt.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open boltdb file %q; is influxd running? %v", path, err)
+	}
+	return db, nil
+}
+
+// nestedBucket walks the bucket path from the root of the transaction.
+func nestedBucket(tx *bolt.Tx, path []string) (*bolt.Bucket, error) {
+	if len(path) == 0 {
+		return nil, errors.New("at least one --bucket must be provided")
+	}
+
+	b := tx.Bucket([]byte(path[0]))
+	for i := 1; b != nil && i < len(path); i++ {
+		b = b.Bucket([]byte(path[i]))
+	}
+	if b == nil {
+		return nil, fmt.Errorf("bucket %q not found", strings.Join(path, " > "))
+	}
+	return b, nil
+}
+
+// inspectKVBucketsF lists the buckets in the bolt file, or beneath the bucket selected with --bucket.
+func inspectKVBucketsF(cmd *cobra.Command, args []string) error {
+	db, err := openBoltReadOnly(inspectKVFlags.boltPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		if len(inspectKVFlags.buckets) == 0 {
+			return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				printBucketTree(os.Stdout, name, b, 0)
+				return nil
+			})
+		}
+
+		b, err := nestedBucket(tx, inspectKVFlags.buckets)
+		if err != nil {
+			return err
+		}
+		printBucketTree(os.Stdout, []byte(inspectKVFlags.buckets[len(inspectKVFlags.buckets)-1]), b, 0)
+		return nil
+	})
+}
+
+func printBucketTree(w io.Writer, name []byte, b *bolt.Bucket, depth int) {
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), formatKVBytes(name))
+	_ = b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if child := b.Bucket(k); child != nil {
+				printBucketTree(w, k, child, depth+1)
+			}
+		}
+		return nil
+	})
+}
+
+// inspectKVDumpF prints the keys and values of the bucket selected with --bucket.
+func inspectKVDumpF(cmd *cobra.Command, args []string) error {
+	if inspectKVFlags.limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if len(inspectKVFlags.buckets) == 0 {
+		return errors.New("at least one --bucket must be provided")
+	}
+
+	db, err := openBoltReadOnly(inspectKVFlags.boltPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	last := []byte(inspectKVFlags.buckets[len(inspectKVFlags.buckets)-1])
+	decodeMeta := !inspectKVFlags.raw && bytes.Equal(last, taskMetaBucket)
+	prefix := []byte(inspectKVFlags.prefix)
+
+	return db.View(func(tx *bolt.Tx) error {
+		b, err := nestedBucket(tx, inspectKVFlags.buckets)
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if inspectKVFlags.limit > 0 && n >= inspectKVFlags.limit {
+				break
+			}
+			n++
+
+			if v == nil {
+				fmt.Fprintf(os.Stdout, "%s\t<bucket>\n", formatKVBytes(k))
+				continue
+			}
+
+			var value string
+			switch {
+			case decodeMeta:
+				value = formatTaskMeta(v)
+			case inspectKVFlags.raw:
+				value = hex.EncodeToString(v)
+			default:
+				value = formatKVBytes(v)
+			}
+			fmt.Fprintf(os.Stdout, "%s\t%s\n", formatKVBytes(k), value)
+		}
+		return nil
+	})
+}
+
+// formatTaskMeta decodes a protobuf encoded StoreTaskMeta and returns it as JSON.
+// If the value cannot be decoded, it is returned hex encoded along with the decoding error.
+func formatTaskMeta(v []byte) string {
+	var stm backend.StoreTaskMeta
+	if err := stm.Unmarshal(v); err != nil {
+		return fmt.Sprintf("%s (failed to decode task meta: %v)", hex.EncodeToString(v), err)
+	}
+	out, err := json.Marshal(stm)
+	if err != nil {
+		return fmt.Sprintf("%s (failed to encode task meta: %v)", hex.EncodeToString(v), err)
+	}
+	return string(out)
+}
+
+// formatKVBytes returns b as a string if it is printable, and hex encoded otherwise.
+func formatKVBytes(b []byte) string {
+	if len(b) == 0 {
+		return `""`
+	}
+	if json.Valid(b) || isPrintable(b) {
+		return string(b)
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if r < ' ' || r == utf8.RuneError || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// inspectKVStatsF reports the number of keys and the size of each bucket.
+func inspectKVStatsF(cmd *cobra.Command, args []string) error {
+	db, err := openBoltReadOnly(inspectKVFlags.boltPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tw := tabwriter.NewWriter(os.Stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, "Bucket\tKeys\tDepth\tLeaf Pages\tBranch Pages\tInuse Bytes\tAlloc Bytes")
+
+	printStats := func(name string, b *bolt.Bucket) {
+		s := b.Stats()
+		inuse := s.BranchInuse + s.LeafInuse + s.InlineBucketInuse
+		alloc := s.BranchAlloc + s.LeafAlloc
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", name, s.KeyN, s.Depth, s.LeafPageN, s.BranchPageN, inuse, alloc)
+	}
+
+	if err := db.View(func(tx *bolt.Tx) error {
+		if len(inspectKVFlags.buckets) == 0 {
+			return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				printStats(formatKVBytes(name), b)
+				return nil
+			})
+		}
+
+		b, err := nestedBucket(tx, inspectKVFlags.buckets)
+		if err != nil {
+			return err
+		}
+		printStats(strings.Join(inspectKVFlags.buckets, " > "), b)
+		return b.ForEach(func(k, v []byte) error {
+			if v != nil {
+				return nil
+			}
+			if child := b.Bucket(k); child != nil {
+				printStats("  "+formatKVBytes(k), child)
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
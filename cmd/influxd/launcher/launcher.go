@@ -2,6 +2,7 @@ package launcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/flux/execute"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/badger"
 	"github.com/influxdata/influxdb/bolt"
 	"github.com/influxdata/influxdb/chronograf/server"
 	"github.com/influxdata/influxdb/gather"
@@ -56,6 +58,8 @@ import (
 const (
 	// BoltStore stores all REST resources in boltdb.
 	BoltStore = "bolt"
+	// BadgerStore stores all REST resources in badger.
+	BadgerStore = "badger"
 	// MemoryStore stores all REST resources in memory (useful for testing).
 	MemoryStore = "memory"
 
@@ -78,6 +82,8 @@ func NewCommand() *cobra.Command {
 			if err := l.run(ctx); err != nil {
 				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
+			} else if l.migrationsDryRun {
+				return
 			} else if !l.Running() {
 				os.Exit(1)
 			}
@@ -140,6 +146,12 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "influxd.bolt"),
 			Desc:    "path to boltdb database",
 		},
+		{
+			DestP:   &l.badgerPath,
+			Flag:    "badger-path",
+			Default: filepath.Join(dir, "influxd.badger"),
+			Desc:    "path to the badger database directory; used when the store is badger",
+		},
 		{
 			DestP: &l.assetsPath,
 			Flag:  "assets-path",
@@ -149,7 +161,7 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.storeType,
 			Flag:    "store",
 			Default: "bolt",
-			Desc:    "backing store for REST resources (bolt or memory)",
+			Desc:    "backing store for REST resources (bolt, badger or memory)",
 		},
 		{
 			DestP:   &l.testing,
@@ -181,6 +193,34 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: 60, // 60 minutes
 			Desc:    "ttl in minutes for newly created sessions",
 		},
+		{
+			DestP:   &l.migrationsDryRun,
+			Flag:    "kv-migrations-dry-run",
+			Default: false,
+			Desc:    "report pending migrations of the REST resources store and exit without applying them",
+		},
+		{
+			DestP: &l.replicaOf,
+			Flag:  "replica-of",
+			Desc:  "URL of a primary influxd; serve its REST resources read-only from periodically refreshed snapshots",
+		},
+		{
+			DestP: &l.replicaToken,
+			Flag:  "replica-token",
+			Desc:  "token used to fetch snapshots from the primary; must be allowed to write all authorizations",
+		},
+		{
+			DestP:   &l.replicaPath,
+			Flag:    "replica-path",
+			Default: filepath.Join(dir, "influxd.replica.bolt"),
+			Desc:    "path to the local copy of the primary's boltdb database",
+		},
+		{
+			DestP:   &l.replicaRefreshInterval,
+			Flag:    "replica-refresh-interval",
+			Default: bolt.DefaultReplicaRefreshInterval,
+			Desc:    "how often a new snapshot is fetched from the primary",
+		},
 		{
 			DestP:   &l.sessionRenewDisabled,
 			Flag:    "session-renew-disabled",
@@ -203,6 +243,12 @@ type Launcher struct {
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	migrationsDryRun     bool
+
+	replicaOf              string
+	replicaToken           string
+	replicaPath            string
+	replicaRefreshInterval time.Duration
 
 	logLevel          string
 	tracingType       string
@@ -210,10 +256,13 @@ type Launcher struct {
 
 	httpBindAddress string
 	boltPath        string
+	badgerPath      string
 	enginePath      string
 	secretStore     string
 
 	boltClient    *bolt.Client
+	badgerStore   *badger.KVStore
+	replicaStore  *bolt.ReplicaKVStore
 	kvService     *kv.Service
 	engine        *storage.Engine
 	StorageConfig storage.Config
@@ -294,6 +343,20 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 		m.logger.Info("failed closing bolt", zap.Error(err))
 	}
 
+	if m.replicaStore != nil {
+		m.logger.Info("Stopping", zap.String("service", "replica"))
+		if err := m.replicaStore.Close(); err != nil {
+			m.logger.Info("failed closing replica", zap.Error(err))
+		}
+	}
+
+	if m.badgerStore != nil {
+		m.logger.Info("Stopping", zap.String("service", "badger"))
+		if err := m.badgerStore.Close(); err != nil {
+			m.logger.Info("failed closing badger", zap.Error(err))
+		}
+	}
+
 	m.logger.Info("Stopping", zap.String("service", "query"))
 	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
 		m.logger.Info("Failed closing query service", zap.Error(err))
@@ -400,31 +463,104 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SessionLength: time.Duration(m.sessionLength) * time.Minute,
 	}
 
-	var flusher http.Flusher
-	switch m.storeType {
-	case BoltStore:
+	var (
+		flusher     http.Flusher
+		kvStore     kv.Store
+		snapshotter http.KVSnapshotter
+		backup      func(ctx context.Context, version uint64) error
+	)
+	switch {
+	case m.replicaOf != "":
+		if m.storeType != BoltStore {
+			err := fmt.Errorf("read replicas require the bolt store; got %s", m.storeType)
+			m.logger.Error("failed opening replica", zap.Error(err))
+			return err
+		}
+		if m.migrationsDryRun {
+			err := errors.New("migrations are applied by the primary; cannot dry run them on a read replica")
+			m.logger.Error("failed opening replica", zap.Error(err))
+			return err
+		}
+		store := bolt.NewReplicaKVStore(m.replicaPath, &http.SnapshotService{
+			Addr:  m.replicaOf,
+			Token: m.replicaToken,
+		})
+		store.RefreshInterval = m.replicaRefreshInterval
+		store.WithLogger(m.logger.With(zap.String("service", "replica")))
+		if err := store.Open(ctx); err != nil {
+			m.logger.Error("failed opening replica", zap.Error(err))
+			return err
+		}
+		m.replicaStore = store
+		m.kvService = kv.NewService(store, serviceConfig)
+		snapshotter = store
+	case m.storeType == BoltStore:
 		store := bolt.NewKVStore(m.boltPath)
 		store.WithDB(m.boltClient.DB())
 		m.kvService = kv.NewService(store, serviceConfig)
 		if m.testing {
 			flusher = store
 		}
-	case MemoryStore:
+		kvStore = store
+		snapshotter = store
+		backup = func(ctx context.Context, version uint64) error {
+			return backupKVStore(ctx, store, fmt.Sprintf("%s.v%d.bak", m.boltPath, version))
+		}
+	case m.storeType == BadgerStore:
+		store := badger.NewKVStore(m.badgerPath)
+		store.WithLogger(m.logger.With(zap.String("service", "badger")))
+		if err := store.Open(ctx); err != nil {
+			m.logger.Error("failed opening badger", zap.Error(err))
+			return err
+		}
+		m.badgerStore = store
+		m.kvService = kv.NewService(store, serviceConfig)
+		if m.testing {
+			flusher = store
+		}
+		kvStore = store
+		backup = func(ctx context.Context, version uint64) error {
+			return backupKVStore(ctx, store, fmt.Sprintf("%s.v%d.bak", m.badgerPath, version))
+		}
+	case m.storeType == MemoryStore:
 		store := inmem.NewKVStore()
 		m.kvService = kv.NewService(store, serviceConfig)
 		if m.testing {
 			flusher = store
 		}
+		kvStore = store
 	default:
-		err := fmt.Errorf("unknown store type %s; expected bolt or memory", m.storeType)
+		err := fmt.Errorf("unknown store type %s; expected bolt, badger or memory", m.storeType)
 		m.logger.Error("failed opening bolt", zap.Error(err))
 		return err
 	}
 
 	m.kvService.Logger = m.logger.With(zap.String("store", "kv"))
-	if err := m.kvService.Initialize(ctx); err != nil {
-		m.logger.Error("failed to initialize kv service", zap.Error(err))
-		return err
+	// Replicas are initialized and migrated by their primary.
+	if m.replicaStore == nil {
+		if err := m.kvService.Initialize(ctx); err != nil {
+			m.logger.Error("failed to initialize kv service", zap.Error(err))
+			return err
+		}
+
+		migrator := kv.NewMigrator(kvStore, m.logger.With(zap.String("service", "kv-migrator")), kv.Migrations...)
+		migrator.DryRun = m.migrationsDryRun
+		migrator.Backup = backup
+		migrations, err := migrator.Up(ctx)
+		if err != nil {
+			m.logger.Error("failed to migrate kv store", zap.Error(err))
+			return err
+		}
+		if m.migrationsDryRun {
+			m.logger.Info("Migration dry run complete", zap.Int("pending", len(migrations)))
+			m.running = false
+			if m.badgerStore != nil {
+				if err := m.badgerStore.Close(); err != nil {
+					return err
+				}
+			}
+			return m.boltClient.Close()
+		}
 	}
 
 	m.reg = prom.NewRegistry()
@@ -541,7 +677,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 		// create the scheduler
 		m.scheduler = taskbackend.NewScheduler(combinedTaskService, executor, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, 100*time.Millisecond), taskbackend.WithLogger(m.logger))
-		m.scheduler.Start(ctx)
+		// Replicas cannot record runs, so only the primary schedules tasks.
+		if m.replicaStore == nil {
+			m.scheduler.Start(ctx)
+		}
 		m.reg.MustRegister(m.scheduler.PrometheusCollectors()...)
 
 		taskSvc = coordinator.New(m.logger.With(zap.String("service", "task-coordinator")), m.scheduler, combinedTaskService)
@@ -581,15 +720,18 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	m.wg.Add(1)
-	go func(logger *zap.Logger) {
-		defer m.wg.Done()
-		logger = logger.With(zap.String("service", "scraper"))
-		if err := scraperScheduler.Run(ctx); err != nil {
-			logger.Error("failed scraper service", zap.Error(err))
-		}
-		logger.Info("Stopping")
-	}(m.logger)
+	// Replicas leave scraping to the primary so that targets are not scraped twice.
+	if m.replicaStore == nil {
+		m.wg.Add(1)
+		go func(logger *zap.Logger) {
+			defer m.wg.Done()
+			logger = logger.With(zap.String("service", "scraper"))
+			if err := scraperScheduler.Run(ctx); err != nil {
+				logger.Error("failed scraper service", zap.Error(err))
+			}
+			logger.Info("Stopping")
+		}(m.logger)
+	}
 
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
@@ -628,6 +770,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SecretService:                   secretSvc,
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
+		StackService:                    m.kvService,
+		AnnotationService:               m.kvService,
+		KVSnapshotter:                   snapshotter,
 		OrgLookupService:                m.kvService,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
@@ -675,6 +820,34 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	return nil
 }
 
+// backupKVStore writes a snapshot of store to path.
+// The snapshot is written to a temporary file first so a partial backup is never left at path.
+func backupKVStore(ctx context.Context, store interface {
+	Backup(context.Context, io.Writer) error
+}, path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Backup(ctx, f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 // OrganizationService returns the internal organization service.
 func (m *Launcher) OrganizationService() platform.OrganizationService {
 	return m.apibackend.OrganizationService
@@ -0,0 +1,397 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	influxdb "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	annotationsPath   = "/api/v2/annotations"
+	annotationsIDPath = "/api/v2/annotations/:id"
+)
+
+// AnnotationBackend is all services and associated parameters required to construct
+// the AnnotationHandler.
+type AnnotationBackend struct {
+	Logger *zap.Logger
+
+	AnnotationService influxdb.AnnotationService
+}
+
+// NewAnnotationBackend returns a new instance of AnnotationBackend.
+func NewAnnotationBackend(b *APIBackend) *AnnotationBackend {
+	return &AnnotationBackend{
+		Logger: b.Logger.With(zap.String("handler", "annotation")),
+
+		AnnotationService: b.AnnotationService,
+	}
+}
+
+// AnnotationHandler is the handler for the annotation service
+type AnnotationHandler struct {
+	*httprouter.Router
+	Logger *zap.Logger
+
+	AnnotationService influxdb.AnnotationService
+}
+
+// NewAnnotationHandler creates a new AnnotationHandler
+func NewAnnotationHandler(b *AnnotationBackend) *AnnotationHandler {
+	h := &AnnotationHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		AnnotationService: b.AnnotationService,
+	}
+
+	h.HandlerFunc("GET", annotationsPath, h.handleGetAnnotations)
+	h.HandlerFunc("POST", annotationsPath, h.handlePostAnnotation)
+	h.HandlerFunc("GET", annotationsIDPath, h.handleGetAnnotation)
+	h.HandlerFunc("DELETE", annotationsIDPath, h.handleDeleteAnnotation)
+	return h
+}
+
+type annotationResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.Annotation
+}
+
+func newAnnotationResponse(a *influxdb.Annotation) *annotationResponse {
+	return &annotationResponse{
+		Links: map[string]string{
+			"self": annotationIDPath(a.ID),
+		},
+		Annotation: a,
+	}
+}
+
+type annotationsResponse struct {
+	Links       map[string]string     `json:"links"`
+	Annotations []*annotationResponse `json:"annotations"`
+}
+
+func decodeGetAnnotationsRequest(ctx context.Context, r *http.Request) (*influxdb.AnnotationFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.AnnotationFilter{}
+
+	orgID, err := influxdb.IDFromString(qp.Get("orgID"))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		}
+	}
+	filter.OrganizationID = *orgID
+
+	if id := qp.Get("dashboardID"); id != "" {
+		dashboardID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		filter.DashboardID = dashboardID
+	}
+
+	if start := qp.Get("start"); start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, start); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "start must be an RFC3339 time",
+				Err:  err,
+			}
+		}
+	}
+	if stop := qp.Get("stop"); stop != "" {
+		if filter.End, err = time.Parse(time.RFC3339, stop); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "stop must be an RFC3339 time",
+				Err:  err,
+			}
+		}
+	}
+
+	for _, tag := range qp["tag"] {
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) != 2 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("tag %q must be of the form key:value", tag),
+			}
+		}
+		if filter.Tags == nil {
+			filter.Tags = map[string]string{}
+		}
+		filter.Tags[kv[0]] = kv[1]
+	}
+
+	return filter, nil
+}
+
+// handleGetAnnotations is the HTTP handler for the GET /api/v2/annotations route.
+func (h *AnnotationHandler) handleGetAnnotations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetAnnotationsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	as, err := h.AnnotationService.FindAnnotations(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := annotationsResponse{
+		Links: map[string]string{
+			"self": annotationsPath,
+		},
+		Annotations: make([]*annotationResponse, 0, len(as)),
+	}
+	for _, a := range as {
+		res.Annotations = append(res.Annotations, newAnnotationResponse(a))
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// handlePostAnnotation is the HTTP handler for the POST /api/v2/annotations route.
+func (h *AnnotationHandler) handlePostAnnotation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a := &influxdb.Annotation{}
+	if err := json.NewDecoder(r.Body).Decode(a); err != nil {
+		EncodeError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid annotation",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.AnnotationService.CreateAnnotation(ctx, a); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newAnnotationResponse(a)); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+func requestAnnotationID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id, err := influxdb.IDFromString(params.ByName("id"))
+	if err != nil {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid annotation id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
+
+// handleGetAnnotation is the HTTP handler for the GET /api/v2/annotations/:id route.
+func (h *AnnotationHandler) handleGetAnnotation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestAnnotationID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	a, err := h.AnnotationService.FindAnnotationByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newAnnotationResponse(a)); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// handleDeleteAnnotation is the HTTP handler for the DELETE /api/v2/annotations/:id route.
+func (h *AnnotationHandler) handleDeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestAnnotationID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.AnnotationService.DeleteAnnotation(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AnnotationService connects to Influx via HTTP using tokens to manage annotations.
+type AnnotationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.AnnotationService = (*AnnotationService)(nil)
+
+// FindAnnotationByID returns a single annotation by ID.
+func (s *AnnotationService) FindAnnotationByID(ctx context.Context, id influxdb.ID) (*influxdb.Annotation, error) {
+	url, err := NewURL(s.Addr, annotationIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(url.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var ar annotationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
+		return nil, err
+	}
+	return ar.Annotation, nil
+}
+
+// FindAnnotations returns the annotations of an organization matching filter.
+func (s *AnnotationService) FindAnnotations(ctx context.Context, filter influxdb.AnnotationFilter) ([]*influxdb.Annotation, error) {
+	url, err := NewURL(s.Addr, annotationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Query()
+	query.Add("orgID", filter.OrganizationID.String())
+	if filter.DashboardID != nil {
+		query.Add("dashboardID", filter.DashboardID.String())
+	}
+	if !filter.Start.IsZero() {
+		query.Add("start", filter.Start.Format(time.RFC3339))
+	}
+	if !filter.End.IsZero() {
+		query.Add("stop", filter.End.Format(time.RFC3339))
+	}
+	for k, v := range filter.Tags {
+		query.Add("tag", k+":"+v)
+	}
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	SetToken(s.Token, req)
+
+	hc := NewClient(url.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var ar annotationsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
+		return nil, err
+	}
+
+	as := make([]*influxdb.Annotation, 0, len(ar.Annotations))
+	for _, a := range ar.Annotations {
+		as = append(as, a.Annotation)
+	}
+	return as, nil
+}
+
+// CreateAnnotation creates a new annotation and sets a.ID with the new identifier.
+func (s *AnnotationService) CreateAnnotation(ctx context.Context, a *influxdb.Annotation) error {
+	url, err := NewURL(s.Addr, annotationsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(url.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(a)
+}
+
+// DeleteAnnotation removes an annotation by ID.
+func (s *AnnotationService) DeleteAnnotation(ctx context.Context, id influxdb.ID) error {
+	url, err := NewURL(s.Addr, annotationIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", url.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(url.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func annotationIDPath(id influxdb.ID) string {
+	return path.Join(annotationsPath, id.String())
+}
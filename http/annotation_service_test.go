@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func initAnnotationService(t *testing.T) (*AnnotationService, func()) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewAnnotationHandler(&AnnotationBackend{
+		Logger:            zap.NewNop(),
+		AnnotationService: svc,
+	})
+	server := httptest.NewServer(handler)
+	client := &AnnotationService{
+		Addr: server.URL,
+	}
+	return client, server.Close
+}
+
+func TestAnnotationService(t *testing.T) {
+	client, done := initAnnotationService(t)
+	defer done()
+
+	ctx := context.Background()
+	orgID := platform.ID(10)
+	t0 := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	deploy := &platform.Annotation{
+		OrganizationID: orgID,
+		Summary:        "deploy",
+		Tags:           map[string]string{"kind": "deploy", "service": "api"},
+		StartTime:      t0,
+	}
+	incident := &platform.Annotation{
+		OrganizationID: orgID,
+		Summary:        "incident",
+		Tags:           map[string]string{"kind": "incident"},
+		StartTime:      t0.Add(time.Hour),
+		EndTime:        t0.Add(2 * time.Hour),
+	}
+	for _, a := range []*platform.Annotation{deploy, incident} {
+		if err := client.CreateAnnotation(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		if !a.ID.Valid() {
+			t.Fatalf("expected created annotation to have an ID, got %+v", a)
+		}
+	}
+
+	as, err := client.FindAnnotations(ctx, platform.AnnotationFilter{
+		OrganizationID: orgID,
+		Tags:           map[string]string{"kind": "deploy", "service": "api"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].ID != deploy.ID {
+		t.Fatalf("expected only the deploy annotation, got %+v", as)
+	}
+
+	as, err = client.FindAnnotations(ctx, platform.AnnotationFilter{
+		OrganizationID: orgID,
+		Start:          t0.Add(30 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].ID != incident.ID {
+		t.Fatalf("expected only the incident annotation, got %+v", as)
+	}
+
+	a, err := client.FindAnnotationByID(ctx, incident.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Summary != "incident" || !a.EndTime.Equal(incident.EndTime) {
+		t.Fatalf("unexpected annotation %+v", a)
+	}
+
+	if err := client.DeleteAnnotation(ctx, incident.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindAnnotationByID(ctx, incident.ID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected deleted annotation not to be found, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/template"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -35,6 +36,9 @@ type APIHandler struct {
 	DocumentHandler      *DocumentHandler
 	SetupHandler         *SetupHandler
 	SessionHandler       *SessionHandler
+	SnapshotHandler      *SnapshotHandler
+	AnnotationHandler    *AnnotationHandler
+	TemplateHandler      *TemplateHandler
 	SwaggerHandler       http.Handler
 }
 
@@ -78,6 +82,9 @@ type APIBackend struct {
 	ChronografService               *server.Service
 	OrgLookupService                authorizer.OrganizationService
 	DocumentService                 influxdb.DocumentService
+	StackService                    influxdb.StackService
+	AnnotationService               influxdb.AnnotationService
+	KVSnapshotter                   KVSnapshotter
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -124,6 +131,10 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
 	h.DashboardHandler = NewDashboardHandler(dashboardBackend)
 
+	annotationBackend := NewAnnotationBackend(b)
+	annotationBackend.AnnotationService = authorizer.NewAnnotationService(b.AnnotationService)
+	h.AnnotationHandler = NewAnnotationHandler(annotationBackend)
+
 	variableBackend := NewVariableBackend(b)
 	variableBackend.VariableService = authorizer.NewVariableService(b.VariableService)
 	h.VariableHandler = NewVariableHandler(variableBackend)
@@ -146,6 +157,9 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	setupBackend := NewSetupBackend(b)
 	h.SetupHandler = NewSetupHandler(setupBackend)
 
+	snapshotBackend := NewSnapshotBackend(b)
+	h.SnapshotHandler = NewSnapshotHandler(snapshotBackend)
+
 	taskBackend := NewTaskBackend(b)
 	h.TaskHandler = NewTaskHandler(taskBackend)
 	h.TaskHandler.UserResourceMappingService = internalURM
@@ -154,6 +168,15 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
 	h.TelegrafHandler = NewTelegrafHandler(telegrafBackend)
 
+	templateBackend := NewTemplateBackend(b)
+	templateBackend.StackService = authorizer.NewStackService(b.StackService)
+	templateBackend.TemplateService = template.NewService(
+		authorizer.NewDashboardService(b.DashboardService),
+		authorizer.NewVariableService(b.VariableService),
+		templateBackend.StackService,
+	)
+	h.TemplateHandler = NewTemplateHandler(templateBackend)
+
 	writeBackend := NewWriteBackend(b)
 	h.WriteHandler = NewWriteHandler(writeBackend)
 
@@ -170,6 +193,7 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"annotations":    "/api/v2/annotations",
 	"authorizations": "/api/v2/authorizations",
 	"buckets":        "/api/v2/buckets",
 	"dashboards":     "/api/v2/dashboards",
@@ -191,16 +215,22 @@ var apiLinks = map[string]interface{}{
 	"signout":  "/api/v2/signout",
 	"sources":  "/api/v2/sources",
 	"scrapers": "/api/v2/scrapers",
+	"snapshot": "/api/v2/snapshot/kv",
 	"swagger":  "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
 		"health":  "/health",
 	},
+	"stacks":    "/api/v2/stacks",
 	"tasks":     "/api/v2/tasks",
 	"telegrafs": "/api/v2/telegrafs",
-	"users":     "/api/v2/users",
-	"write":     "/api/v2/write",
+	"templates": map[string]string{
+		"export": "/api/v2/templates/export",
+		"apply":  "/api/v2/templates/apply",
+	},
+	"users": "/api/v2/users",
+	"write": "/api/v2/write",
 }
 
 func (h *APIHandler) serveLinks(w http.ResponseWriter, r *http.Request) {
@@ -234,6 +264,11 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/snapshot") {
+		h.SnapshotHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/write") {
 		h.WriteHandler.ServeHTTP(w, r)
 		return
@@ -279,6 +314,11 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/annotations") {
+		h.AnnotationHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/sources") {
 		h.SourceHandler.ServeHTTP(w, r)
 		return
@@ -304,6 +344,11 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/templates") || strings.HasPrefix(r.URL.Path, "/api/v2/stacks") {
+		h.TemplateHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/documents") {
 		h.DocumentHandler.ServeHTTP(w, r)
 		return
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	influxdb "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// KVSnapshotter writes a consistent snapshot of the metadata store to w.
+type KVSnapshotter interface {
+	Backup(ctx context.Context, w io.Writer) error
+}
+
+// SnapshotBackend is all services and associated parameters required to construct
+// the SnapshotHandler.
+type SnapshotBackend struct {
+	Logger *zap.Logger
+
+	KVSnapshotter KVSnapshotter
+}
+
+// NewSnapshotBackend returns a new instance of SnapshotBackend.
+func NewSnapshotBackend(b *APIBackend) *SnapshotBackend {
+	return &SnapshotBackend{
+		Logger: b.Logger.With(zap.String("handler", "snapshot")),
+
+		KVSnapshotter: b.KVSnapshotter,
+	}
+}
+
+// SnapshotHandler serves snapshots of the metadata store to read replicas.
+type SnapshotHandler struct {
+	*httprouter.Router
+	Logger *zap.Logger
+
+	KVSnapshotter KVSnapshotter
+}
+
+const (
+	snapshotKVPath = "/api/v2/snapshot/kv"
+)
+
+// NewSnapshotHandler returns a new instance of SnapshotHandler.
+func NewSnapshotHandler(b *SnapshotBackend) *SnapshotHandler {
+	h := &SnapshotHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		KVSnapshotter: b.KVSnapshotter,
+	}
+
+	h.HandlerFunc("GET", snapshotKVPath, h.handleGetKVSnapshot)
+	return h
+}
+
+// snapshotPermission is required to fetch a snapshot. A snapshot contains every
+// token, so only tokens that may already write any authorization are allowed.
+var snapshotPermission = influxdb.Permission{
+	Action: influxdb.WriteAction,
+	Resource: influxdb.Resource{
+		Type: influxdb.AuthorizationsResourceType,
+	},
+}
+
+// handleGetKVSnapshot is the HTTP handler for the GET /api/v2/snapshot/kv route.
+func (h *SnapshotHandler) handleGetKVSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := authorizer.IsAllowed(ctx, snapshotPermission); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if h.KVSnapshotter == nil {
+		EncodeError(ctx, &influxdb.Error{
+			Code: influxdb.EMethodNotAllowed,
+			Msg:  "the metadata store does not support snapshots",
+		}, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	if err := h.KVSnapshotter.Backup(ctx, w); err != nil {
+		// The status has already been written, so the error can only be logged.
+		h.Logger.Info("Failed to write snapshot", zap.Error(err))
+	}
+}
+
+// SnapshotService fetches snapshots of the metadata store of a remote influxd.
+type SnapshotService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+// Backup writes a snapshot of the remote metadata store to w.
+func (s *SnapshotService) Backup(ctx context.Context, w io.Writer) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, snapshotKVPath)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+	tracing.InjectToHTTPRequest(span, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,303 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	influxdb "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	templatesExportPath = "/api/v2/templates/export"
+	templatesApplyPath  = "/api/v2/templates/apply"
+	stacksPath          = "/api/v2/stacks"
+)
+
+// TemplateBackend is all services and associated parameters required to construct
+// the TemplateHandler.
+type TemplateBackend struct {
+	Logger *zap.Logger
+
+	TemplateService influxdb.TemplateService
+	StackService    influxdb.StackService
+}
+
+// NewTemplateBackend returns a new instance of TemplateBackend.
+func NewTemplateBackend(b *APIBackend) *TemplateBackend {
+	return &TemplateBackend{
+		Logger: b.Logger.With(zap.String("handler", "template")),
+
+		StackService: b.StackService,
+	}
+}
+
+// TemplateHandler represents an HTTP API handler for templates and the stacks they create.
+type TemplateHandler struct {
+	*httprouter.Router
+	Logger *zap.Logger
+
+	TemplateService influxdb.TemplateService
+	StackService    influxdb.StackService
+}
+
+// NewTemplateHandler returns a new instance of TemplateHandler.
+func NewTemplateHandler(b *TemplateBackend) *TemplateHandler {
+	h := &TemplateHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		TemplateService: b.TemplateService,
+		StackService:    b.StackService,
+	}
+
+	h.HandlerFunc("POST", templatesExportPath, h.handleExportTemplate)
+	h.HandlerFunc("POST", templatesApplyPath, h.handleApplyTemplate)
+	h.HandlerFunc("GET", stacksPath, h.handleGetStacks)
+	h.HandlerFunc("GET", stacksPath+"/:id", h.handleGetStack)
+	h.HandlerFunc("DELETE", stacksPath+"/:id", h.handleDeleteStack)
+	return h
+}
+
+type exportTemplateRequest struct {
+	OrgID        influxdb.ID   `json:"orgID"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	DashboardIDs []influxdb.ID `json:"dashboards"`
+}
+
+// handleExportTemplate is the HTTP handler for the POST /api/v2/templates/export route.
+// The template is encoded as YAML if the request accepts it, and as JSON otherwise.
+func (h *TemplateHandler) handleExportTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req exportTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		EncodeError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid export request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.OrgID.Valid() {
+		EncodeError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}, w)
+		return
+	}
+
+	t, err := h.TemplateService.ExportDashboards(ctx, req.OrgID, req.DashboardIDs)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if req.Name != "" {
+		t.Meta.Name = req.Name
+	}
+	if req.Description != "" {
+		t.Meta.Description = req.Description
+	}
+
+	if !isYAML(r.Header.Get("Accept")) {
+		if err := encodeResponse(ctx, w, http.StatusOK, t); err != nil {
+			logEncodingError(h.Logger, r, err)
+		}
+		return
+	}
+
+	b, err := yaml.Marshal(t)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-yaml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+type applyTemplateRequest struct {
+	OrgID    influxdb.ID       `json:"orgID"`
+	StackID  *influxdb.ID      `json:"stackID,omitempty"`
+	Buckets  map[string]string `json:"buckets,omitempty"`
+	Template influxdb.Template `json:"template"`
+}
+
+func decodeApplyTemplateRequest(ctx context.Context, r *http.Request) (*applyTemplateRequest, error) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if isYAML(r.Header.Get("Content-Type")) {
+		if b, err = yaml.YAMLToJSON(b); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid yaml",
+				Err:  err,
+			}
+		}
+	}
+
+	req := &applyTemplateRequest{}
+	if err := json.Unmarshal(b, req); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid apply request",
+			Err:  err,
+		}
+	}
+	if !req.OrgID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	return req, nil
+}
+
+// handleApplyTemplate is the HTTP handler for the POST /api/v2/templates/apply route.
+func (h *TemplateHandler) handleApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeApplyTemplateRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	st, err := h.TemplateService.ApplyTemplate(ctx, req.OrgID, &req.Template, influxdb.TemplateApplyOptions{
+		StackID: req.StackID,
+		Buckets: req.Buckets,
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newStackResponse(st)); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+type stackResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.Stack
+}
+
+func newStackResponse(st *influxdb.Stack) *stackResponse {
+	return &stackResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", stacksPath, st.ID),
+		},
+		Stack: st,
+	}
+}
+
+type stacksResponse struct {
+	Links  map[string]string `json:"links"`
+	Stacks []*stackResponse  `json:"stacks"`
+}
+
+// handleGetStacks is the HTTP handler for the GET /api/v2/stacks route.
+func (h *TemplateHandler) handleGetStacks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter influxdb.StackFilter
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		filter.OrganizationID = id
+	}
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	stacks, err := h.StackService.FindStacks(ctx, filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := stacksResponse{
+		Links: map[string]string{
+			"self": stacksPath,
+		},
+		Stacks: make([]*stackResponse, 0, len(stacks)),
+	}
+	for _, st := range stacks {
+		res.Stacks = append(res.Stacks, newStackResponse(st))
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+func requestStackID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id, err := influxdb.IDFromString(params.ByName("id"))
+	if err != nil {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid stack id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
+
+// handleGetStack is the HTTP handler for the GET /api/v2/stacks/:id route.
+func (h *TemplateHandler) handleGetStack(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestStackID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	st, err := h.StackService.FindStackByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newStackResponse(st)); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// handleDeleteStack is the HTTP handler for the DELETE /api/v2/stacks/:id route.
+// It removes every resource created by the stack.
+func (h *TemplateHandler) handleDeleteStack(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestStackID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.TemplateService.RemoveStack(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func isYAML(contentType string) bool {
+	return strings.Contains(contentType, "yaml")
+}
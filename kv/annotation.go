@@ -0,0 +1,267 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+var (
+	annotationBucket    = []byte("annotationsv1")
+	annotationOrgsIndex = []byte("annotationorgsv1")
+)
+
+var _ influxdb.AnnotationService = (*Service)(nil)
+
+func (s *Service) initializeAnnotations(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(annotationBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(annotationOrgsIndex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindAnnotationByID returns a single annotation by ID.
+func (s *Service) FindAnnotationByID(ctx context.Context, id influxdb.ID) (*influxdb.Annotation, error) {
+	var a *influxdb.Annotation
+	err := s.kv.View(ctx, func(tx Tx) error {
+		v, err := s.findAnnotationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		a = v
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindAnnotationByID,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+func (s *Service) findAnnotationByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Annotation, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(annotationBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrAnnotationNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := &influxdb.Annotation{}
+	if err := json.Unmarshal(v, a); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+// FindAnnotations returns the annotations of an organization matching filter,
+// ordered by start time.
+func (s *Service) FindAnnotations(ctx context.Context, filter influxdb.AnnotationFilter) ([]*influxdb.Annotation, error) {
+	annotations := []*influxdb.Annotation{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		idx, err := tx.Bucket(annotationOrgsIndex)
+		if err != nil {
+			return err
+		}
+		cur, err := idx.Cursor()
+		if err != nil {
+			return err
+		}
+
+		prefix, err := filter.OrganizationID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		// The index is ordered by start time, so the scan stops at the
+		// first annotation starting after the end of the filter.
+		for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			if len(k) != 2*influxdb.IDLength+8 {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "malformed annotation orgs index key (please report this error)",
+				}
+			}
+			if !filter.End.IsZero() && decodeAnnotationTime(k[influxdb.IDLength:]).After(filter.End) {
+				break
+			}
+
+			var id influxdb.ID
+			if err := id.Decode(k[influxdb.IDLength+8:]); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "malformed annotation orgs index key (please report this error)",
+					Err:  err,
+				}
+			}
+			a, err := s.findAnnotationByID(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if filter.Match(a) {
+				annotations = append(annotations, a)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindAnnotations,
+			Err: err,
+		}
+	}
+	return annotations, nil
+}
+
+// CreateAnnotation creates a new annotation and sets a.ID with the new identifier.
+// An annotation without an end time marks a single instant.
+func (s *Service) CreateAnnotation(ctx context.Context, a *influxdb.Annotation) error {
+	if a.EndTime.IsZero() {
+		a.EndTime = a.StartTime
+	}
+	if err := a.Valid(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateAnnotation,
+			Err: err,
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		a.ID = s.IDGenerator.ID()
+		a.CreatedAt = s.Now()
+
+		v, err := json.Marshal(a)
+		if err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		key, err := encodeAnnotationOrgsIndex(a)
+		if err != nil {
+			return err
+		}
+		idx, err := tx.Bucket(annotationOrgsIndex)
+		if err != nil {
+			return err
+		}
+		if err := idx.Put(key, nil); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(annotationBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key[influxdb.IDLength+8:], v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateAnnotation,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation by ID.
+func (s *Service) DeleteAnnotation(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		a, err := s.findAnnotationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		key, err := encodeAnnotationOrgsIndex(a)
+		if err != nil {
+			return err
+		}
+		idx, err := tx.Bucket(annotationOrgsIndex)
+		if err != nil {
+			return err
+		}
+		if err := idx.Delete(key); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(annotationBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(key[influxdb.IDLength+8:])
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteAnnotation,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// encodeAnnotationOrgsIndex returns the key orgID + start time + ID, so that
+// the annotations of an organization are ordered by start time.
+func encodeAnnotationOrgsIndex(a *influxdb.Annotation) ([]byte, error) {
+	orgID, err := a.OrganizationID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	id, err := a.ID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	key := make([]byte, 0, 2*influxdb.IDLength+8)
+	key = append(key, orgID...)
+	key = append(key, encodeAnnotationTime(a.StartTime)...)
+	key = append(key, id...)
+	return key, nil
+}
+
+// encodeAnnotationTime encodes t so that times before the epoch sort before
+// the ones after it.
+func encodeAnnotationTime(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano())^(1<<63))
+	return b
+}
+
+func decodeAnnotationTime(b []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)^(1<<63))).UTC()
+}
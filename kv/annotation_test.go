@@ -0,0 +1,120 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestBoltAnnotationService(t *testing.T) {
+	testAnnotationService(t, NewTestBoltStore)
+}
+
+func TestInmemAnnotationService(t *testing.T) {
+	testAnnotationService(t, NewTestInmemStore)
+}
+
+func testAnnotationService(t *testing.T, newStore func() (kv.Store, func(), error)) {
+	store, closeFn, err := newStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFn()
+
+	ctx := context.Background()
+	svc := kv.NewService(store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		orgA, orgB = influxdb.ID(10), influxdb.ID(20)
+		dashA      = influxdb.ID(30)
+		dashB      = influxdb.ID(40)
+		t0         = time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	deploy := &influxdb.Annotation{
+		OrganizationID: orgA,
+		Summary:        "deploy",
+		Tags:           map[string]string{"kind": "deploy"},
+		StartTime:      t0.Add(2 * time.Hour),
+	}
+	incident := &influxdb.Annotation{
+		OrganizationID: orgA,
+		DashboardID:    &dashA,
+		Summary:        "incident",
+		Tags:           map[string]string{"kind": "incident"},
+		StartTime:      t0,
+		EndTime:        t0.Add(time.Hour),
+	}
+	other := &influxdb.Annotation{
+		OrganizationID: orgA,
+		DashboardID:    &dashB,
+		Summary:        "other dashboard",
+		StartTime:      t0,
+	}
+	elsewhere := &influxdb.Annotation{
+		OrganizationID: orgB,
+		Summary:        "other org",
+		StartTime:      t0,
+	}
+	for _, a := range []*influxdb.Annotation{deploy, incident, other, elsewhere} {
+		if err := svc.CreateAnnotation(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !deploy.EndTime.Equal(deploy.StartTime) {
+		t.Errorf("expected annotation without end time to end at its start, got %v", deploy.EndTime)
+	}
+
+	find := func(filter influxdb.AnnotationFilter) []string {
+		t.Helper()
+		as, err := svc.FindAnnotations(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var summaries []string
+		for _, a := range as {
+			summaries = append(summaries, a.Summary)
+		}
+		return summaries
+	}
+	equal := func(got []string, exp ...string) {
+		t.Helper()
+		if len(got) != len(exp) {
+			t.Fatalf("expected annotations %v, got %v", exp, got)
+		}
+		for i := range exp {
+			if got[i] != exp[i] {
+				t.Fatalf("expected annotations %v, got %v", exp, got)
+			}
+		}
+	}
+
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA}), "incident", "other dashboard", "deploy")
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA, DashboardID: &dashA}), "incident", "deploy")
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA, Start: t0.Add(30 * time.Minute), End: t0.Add(90 * time.Minute)}), "incident")
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA, Start: t0.Add(90 * time.Minute)}), "deploy")
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA, Tags: map[string]string{"kind": "deploy"}}), "deploy")
+
+	if err := svc.CreateAnnotation(ctx, &influxdb.Annotation{
+		OrganizationID: orgA,
+		Summary:        "inverted",
+		StartTime:      t0,
+		EndTime:        t0.Add(-time.Hour),
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected inverted time range to be invalid, got %v", err)
+	}
+
+	if err := svc.DeleteAnnotation(ctx, incident.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAnnotationByID(ctx, incident.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted annotation not to be found, got %v", err)
+	}
+	equal(find(influxdb.AnnotationFilter{OrganizationID: orgA, DashboardID: &dashA}), "deploy")
+}
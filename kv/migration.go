@@ -0,0 +1,257 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Migration Storage Schema
+// migrationBucket:
+//   <version>: JSON encoded MigrationRecord for every applied migration,
+//              keyed by the big-endian uint64 version so the last key is the current version.
+
+var migrationBucket = []byte("migrationsv1")
+
+// Migration is a single versioned change to the layout of the data held in a Store.
+type Migration struct {
+	// Version orders the migrations. Versions must be unique and greater than zero.
+	Version uint64
+	// Name is a short human readable description of the change.
+	Name string
+	// Up applies the migration. It is called inside of a single update transaction,
+	// so either all or none of its changes are persisted.
+	Up func(ctx context.Context, tx Tx) error
+}
+
+// MigrationRecord is stored for every migration that has been applied to a Store.
+type MigrationRecord struct {
+	Version   uint64    `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// Migrations are the migrations applied to the platform key value layout, in order.
+// New layout changes must be appended with the next version instead of modifying
+// the buckets directly in their handlers.
+var Migrations = []Migration{}
+
+// Migrator applies pending migrations to a Store.
+type Migrator struct {
+	Store      Store
+	Logger     *zap.Logger
+	Migrations []Migration
+
+	// DryRun reports the pending migrations without applying them.
+	DryRun bool
+
+	// Backup, if set, is called once before any pending migration is applied.
+	// current is the version of the store at the time of the backup.
+	// Migration is aborted if Backup returns an error.
+	Backup func(ctx context.Context, current uint64) error
+
+	influxdb.TimeGenerator
+}
+
+// NewMigrator returns a Migrator for the provided store and migrations.
+func NewMigrator(store Store, logger *zap.Logger, migrations ...Migration) *Migrator {
+	return &Migrator{
+		Store:         store,
+		Logger:        logger,
+		Migrations:    migrations,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// Initialize creates the bucket recording applied migrations.
+func (m *Migrator) Initialize(ctx context.Context) error {
+	return m.Store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(migrationBucket)
+		return err
+	})
+}
+
+// Version returns the version of the most recently applied migration, or zero if none were applied.
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	if err := m.Initialize(ctx); err != nil {
+		return 0, err
+	}
+
+	var version uint64
+	err := m.Store.View(ctx, func(tx Tx) error {
+		v, err := migrationVersion(tx)
+		if err != nil {
+			return err
+		}
+		version = v
+		return nil
+	})
+	return version, err
+}
+
+// Pending returns the migrations that have not been applied to the store yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, mig := range m.Migrations {
+		if mig.Version > version {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction.
+// The applied migrations are returned; in dry run mode the migrations that
+// would have been applied are returned instead.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	if m.DryRun {
+		for _, mig := range pending {
+			m.Logger.Info("Pending kv migration", zap.Uint64("version", mig.Version), zap.String("name", mig.Name))
+		}
+		return pending, nil
+	}
+
+	if m.Backup != nil {
+		version, err := m.Version(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.Backup(ctx, version); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "unable to back up store before migrating",
+				Op:   OpPrefix + "Migrate",
+				Err:  err,
+			}
+		}
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, mig := range pending {
+		m.Logger.Info("Applying kv migration", zap.Uint64("version", mig.Version), zap.String("name", mig.Name))
+		err := m.Store.Update(ctx, func(tx Tx) error {
+			if err := mig.Up(ctx, tx); err != nil {
+				return err
+			}
+			return putMigrationRecord(tx, MigrationRecord{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: m.Now(),
+			})
+		})
+		if err != nil {
+			return applied, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("failed to apply migration %d (%s)", mig.Version, mig.Name),
+				Op:   OpPrefix + "Migrate",
+				Err:  err,
+			}
+		}
+		applied = append(applied, mig)
+	}
+	return applied, nil
+}
+
+// Applied returns the records of all migrations applied to the store, in order.
+func (m *Migrator) Applied(ctx context.Context) ([]MigrationRecord, error) {
+	if err := m.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	var records []MigrationRecord
+	err := m.Store.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(migrationBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			var r MigrationRecord
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// validate ensures the migrations have valid versions and are in order.
+func (m *Migrator) validate() error {
+	var last uint64
+	for _, mig := range m.Migrations {
+		if mig.Version <= last {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("migration %d (%s) is out of order; versions must be positive and increasing", mig.Version, mig.Name),
+				Op:   OpPrefix + "Migrate",
+			}
+		}
+		if mig.Up == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("migration %d (%s) has no Up function", mig.Version, mig.Name),
+				Op:   OpPrefix + "Migrate",
+			}
+		}
+		last = mig.Version
+	}
+	return nil
+}
+
+func migrationVersion(tx Tx) (uint64, error) {
+	b, err := tx.Bucket(migrationBucket)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return 0, err
+	}
+	k, _ := cur.Last()
+	if k == nil {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(k), nil
+}
+
+func putMigrationRecord(tx Tx, r MigrationRecord) error {
+	b, err := tx.Bucket(migrationBucket)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, r.Version)
+	return b.Put(k, v)
+}
@@ -0,0 +1,184 @@
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestMigrator_Bolt(t *testing.T) {
+	testMigrator(t, NewTestBoltStore)
+}
+
+func TestMigrator_Inmem(t *testing.T) {
+	testMigrator(t, NewTestInmemStore)
+}
+
+func testMigrator(t *testing.T, newStore func() (kv.Store, func(), error)) {
+	var (
+		bucket = []byte("migrationtestv1")
+		key    = []byte("key")
+	)
+
+	migrations := []kv.Migration{
+		{
+			Version: 1,
+			Name:    "add key",
+			Up: func(ctx context.Context, tx kv.Tx) error {
+				b, err := tx.Bucket(bucket)
+				if err != nil {
+					return err
+				}
+				return b.Put(key, []byte("1"))
+			},
+		},
+		{
+			Version: 2,
+			Name:    "update key",
+			Up: func(ctx context.Context, tx kv.Tx) error {
+				b, err := tx.Bucket(bucket)
+				if err != nil {
+					return err
+				}
+				return b.Put(key, []byte("2"))
+			},
+		},
+	}
+
+	value := func(t *testing.T, s kv.Store) string {
+		t.Helper()
+		var v []byte
+		if err := s.Update(context.Background(), func(tx kv.Tx) error {
+			b, err := tx.Bucket(bucket)
+			if err != nil {
+				return err
+			}
+			v, err = b.Get(key)
+			if kv.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return string(v)
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		s, closeFn, err := newStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeFn()
+
+		m := kv.NewMigrator(s, zap.NewNop(), migrations...)
+		m.DryRun = true
+		m.Backup = func(context.Context, uint64) error {
+			t.Fatal("backup should not be called in dry run mode")
+			return nil
+		}
+		pending, err := m.Up(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 2 {
+			t.Fatalf("expected 2 pending migrations, got %d", len(pending))
+		}
+		if v := value(t, s); v != "" {
+			t.Fatalf("expected dry run not to modify the store, got value %q", v)
+		}
+		if version, err := m.Version(context.Background()); err != nil || version != 0 {
+			t.Fatalf("expected version 0, got %d (%v)", version, err)
+		}
+	})
+
+	t.Run("up", func(t *testing.T) {
+		s, closeFn, err := newStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeFn()
+
+		// Apply only the first migration, then the remaining one.
+		m := kv.NewMigrator(s, zap.NewNop(), migrations[:1]...)
+		if _, err := m.Up(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if v := value(t, s); v != "1" {
+			t.Fatalf("expected value 1, got %q", v)
+		}
+
+		var backups []uint64
+		m = kv.NewMigrator(s, zap.NewNop(), migrations...)
+		m.Backup = func(_ context.Context, version uint64) error {
+			backups = append(backups, version)
+			return nil
+		}
+		applied, err := m.Up(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 1 || applied[0].Version != 2 {
+			t.Fatalf("expected only migration 2 to be applied, got %v", applied)
+		}
+		if len(backups) != 1 || backups[0] != 1 {
+			t.Fatalf("expected one backup at version 1, got %v", backups)
+		}
+		if v := value(t, s); v != "2" {
+			t.Fatalf("expected value 2, got %q", v)
+		}
+
+		// Nothing left to apply, so no backup is taken.
+		applied, err = m.Up(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 0 || len(backups) != 1 {
+			t.Fatalf("expected no migrations or backups, got %v and %v", applied, backups)
+		}
+
+		records, err := m.Applied(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[0].Name != "add key" || records[1].Name != "update key" {
+			t.Fatalf("unexpected migration records %+v", records)
+		}
+	})
+
+	t.Run("failed backup", func(t *testing.T) {
+		s, closeFn, err := newStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeFn()
+
+		m := kv.NewMigrator(s, zap.NewNop(), migrations...)
+		m.Backup = func(context.Context, uint64) error {
+			return errors.New("disk full")
+		}
+		if _, err := m.Up(context.Background()); err == nil {
+			t.Fatal("expected error when backup fails")
+		}
+		if v := value(t, s); v != "" {
+			t.Fatalf("expected store not to be modified, got value %q", v)
+		}
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		s, closeFn, err := newStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeFn()
+
+		m := kv.NewMigrator(s, zap.NewNop(), migrations[1], migrations[0])
+		if _, err := m.Up(context.Background()); err == nil {
+			t.Fatal("expected error for out of order migrations")
+		}
+	})
+}
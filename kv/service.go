@@ -74,6 +74,10 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeAnnotations(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeKVLog(ctx, tx); err != nil {
 			return err
 		}
@@ -114,6 +118,10 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeStacks(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeTelegraf(ctx, tx); err != nil {
 			return err
 		}
@@ -0,0 +1,288 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+var (
+	stackBucket    = []byte("stacksv1")
+	stackOrgsIndex = []byte("stackorgsv1")
+)
+
+var _ influxdb.StackService = (*Service)(nil)
+
+func (s *Service) initializeStacks(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(stackBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(stackOrgsIndex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindStackByID returns a single stack by ID.
+func (s *Service) FindStackByID(ctx context.Context, id influxdb.ID) (*influxdb.Stack, error) {
+	var st *influxdb.Stack
+	err := s.kv.View(ctx, func(tx Tx) error {
+		v, err := s.findStackByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		st = v
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindStackByID,
+			Err: err,
+		}
+	}
+	return st, nil
+}
+
+func (s *Service) findStackByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Stack, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(stackBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrStackNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st := &influxdb.Stack{}
+	if err := json.Unmarshal(v, st); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return st, nil
+}
+
+// FindStacks returns the stacks matching filter.
+func (s *Service) FindStacks(ctx context.Context, filter influxdb.StackFilter) ([]*influxdb.Stack, error) {
+	stacks := []*influxdb.Stack{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		filterFn := func(st *influxdb.Stack) bool {
+			return filter.Name == nil || st.Name == *filter.Name
+		}
+
+		if filter.OrganizationID != nil {
+			return s.forEachOrganizationStack(ctx, tx, *filter.OrganizationID, func(st *influxdb.Stack) {
+				if filterFn(st) {
+					stacks = append(stacks, st)
+				}
+			})
+		}
+
+		b, err := tx.Bucket(stackBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			st := &influxdb.Stack{}
+			if err := json.Unmarshal(v, st); err != nil {
+				return err
+			}
+			if filterFn(st) {
+				stacks = append(stacks, st)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindStacks,
+			Err: err,
+		}
+	}
+	return stacks, nil
+}
+
+func (s *Service) forEachOrganizationStack(ctx context.Context, tx Tx, orgID influxdb.ID, fn func(*influxdb.Stack)) error {
+	idx, err := tx.Bucket(stackOrgsIndex)
+	if err != nil {
+		return err
+	}
+	cur, err := idx.Cursor()
+	if err != nil {
+		return err
+	}
+
+	prefix, err := orgID.Encode()
+	if err != nil {
+		return err
+	}
+
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		var id influxdb.ID
+		if err := id.Decode(k[influxdb.IDLength:]); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "malformed stack orgs index key (please report this error)",
+				Err:  err,
+			}
+		}
+		st, err := s.findStackByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		fn(st)
+	}
+	return nil
+}
+
+// CreateStack creates a new stack and sets st.ID with the new identifier.
+func (s *Service) CreateStack(ctx context.Context, st *influxdb.Stack) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		st.ID = s.IDGenerator.ID()
+		st.CreatedAt = s.Now()
+		st.UpdatedAt = st.CreatedAt
+		return s.putStack(ctx, tx, st)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateStack,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// ReplaceStack replaces the stack with the ID of st.
+func (s *Service) ReplaceStack(ctx context.Context, st *influxdb.Stack) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		prev, err := s.findStackByID(ctx, tx, st.ID)
+		if err != nil {
+			return err
+		}
+		if prev.OrganizationID != st.OrganizationID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "a stack cannot be moved to another organization",
+			}
+		}
+		st.CreatedAt = prev.CreatedAt
+		st.UpdatedAt = s.Now()
+		return s.putStack(ctx, tx, st)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpReplaceStack,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putStack(ctx context.Context, tx Tx, st *influxdb.Stack) error {
+	v, err := json.Marshal(st)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	key, err := encodeStackOrgsIndex(st)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(stackOrgsIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(key, nil); err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(stackBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key[influxdb.IDLength:], v)
+}
+
+// DeleteStack removes the record of a stack.
+func (s *Service) DeleteStack(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		st, err := s.findStackByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		key, err := encodeStackOrgsIndex(st)
+		if err != nil {
+			return err
+		}
+		idx, err := tx.Bucket(stackOrgsIndex)
+		if err != nil {
+			return err
+		}
+		if err := idx.Delete(key); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(stackBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(key[influxdb.IDLength:])
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteStack,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// encodeStackOrgsIndex returns the index key of a stack, the encoded
+// organization ID followed by the encoded stack ID.
+func encodeStackOrgsIndex(st *influxdb.Stack) ([]byte, error) {
+	orgID, err := st.OrganizationID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad organization id",
+			Err:  err,
+		}
+	}
+	id, err := st.ID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad stack id",
+			Err:  err,
+		}
+	}
+
+	key := make([]byte, 0, 2*influxdb.IDLength)
+	key = append(key, orgID...)
+	key = append(key, id...)
+	return key, nil
+}
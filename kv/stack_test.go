@@ -0,0 +1,90 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestBoltStackService(t *testing.T) {
+	testStackService(t, NewTestBoltStore)
+}
+
+func TestInmemStackService(t *testing.T) {
+	testStackService(t, NewTestInmemStore)
+}
+
+func testStackService(t *testing.T, newStore func() (kv.Store, func(), error)) {
+	store, closeFn, err := newStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFn()
+
+	ctx := context.Background()
+	svc := kv.NewService(store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	orgA, orgB := influxdb.ID(10), influxdb.ID(20)
+	a := &influxdb.Stack{OrganizationID: orgA, Name: "a"}
+	if err := svc.CreateStack(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	b := &influxdb.Stack{OrganizationID: orgB, Name: "b"}
+	if err := svc.CreateStack(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	stacks, err := svc.FindStacks(ctx, influxdb.StackFilter{OrganizationID: &orgA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stacks) != 1 || stacks[0].ID != a.ID {
+		t.Fatalf("expected only stack a in org A, got %+v", stacks)
+	}
+
+	name := "b"
+	stacks, err = svc.FindStacks(ctx, influxdb.StackFilter{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stacks) != 1 || stacks[0].ID != b.ID {
+		t.Fatalf("expected only stack b, got %+v", stacks)
+	}
+
+	a.Resources = []influxdb.StackResource{{Type: influxdb.DashboardsResourceType, ID: 3, Name: "dash"}}
+	if err := svc.ReplaceStack(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.FindStackByID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := got.Resource(influxdb.DashboardsResourceType, "dash"); !ok || r.ID != 3 {
+		t.Fatalf("expected replaced stack to contain dashboard 3, got %+v", got.Resources)
+	}
+
+	moved := *a
+	moved.OrganizationID = orgB
+	if err := svc.ReplaceStack(ctx, &moved); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected moving a stack to another organization to be invalid, got %v", err)
+	}
+
+	if err := svc.DeleteStack(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindStackByID(ctx, a.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted stack not to be found, got %v", err)
+	}
+	stacks, err = svc.FindStacks(ctx, influxdb.StackFilter{OrganizationID: &orgA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stacks) != 0 {
+		t.Fatalf("expected no stacks in org A, got %+v", stacks)
+	}
+}
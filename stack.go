@@ -0,0 +1,72 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrStackNotFound is the error msg for a missing stack.
+const ErrStackNotFound = "stack not found"
+
+// ops for stack service.
+const (
+	OpFindStackByID = "FindStackByID"
+	OpFindStacks    = "FindStacks"
+	OpCreateStack   = "CreateStack"
+	OpReplaceStack  = "ReplaceStack"
+	OpDeleteStack   = "DeleteStack"
+)
+
+// StackService stores the records of the resources created by applying templates.
+type StackService interface {
+	// FindStackByID returns a single stack by ID.
+	FindStackByID(ctx context.Context, id ID) (*Stack, error)
+
+	// FindStacks returns the stacks matching filter.
+	FindStacks(ctx context.Context, filter StackFilter) ([]*Stack, error)
+
+	// CreateStack creates a new stack and sets s.ID with the new identifier.
+	CreateStack(ctx context.Context, s *Stack) error
+
+	// ReplaceStack replaces a stack, typically after its template was applied again.
+	ReplaceStack(ctx context.Context, s *Stack) error
+
+	// DeleteStack removes the record of a stack. The resources it references are not removed.
+	DeleteStack(ctx context.Context, id ID) error
+}
+
+// Stack tracks the resources created in an organization by applying a template,
+// so that applying it again updates them in place and removing it deletes them.
+type Stack struct {
+	ID             ID              `json:"id,omitempty"`
+	OrganizationID ID              `json:"orgID,omitempty"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	Resources      []StackResource `json:"resources"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// StackResource is a resource created by applying a template.
+type StackResource struct {
+	Type ResourceType `json:"type"`
+	ID   ID           `json:"id"`
+	// Name is the name of the resource in the template it was created from.
+	Name string `json:"name"`
+}
+
+// Resource returns the resource in the stack with the provided type and template name.
+func (s *Stack) Resource(typ ResourceType, name string) (StackResource, bool) {
+	for _, r := range s.Resources {
+		if r.Type == typ && r.Name == name {
+			return r, true
+		}
+	}
+	return StackResource{}, false
+}
+
+// StackFilter is a filter for stacks.
+type StackFilter struct {
+	OrganizationID *ID
+	Name           *string
+}
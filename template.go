@@ -0,0 +1,138 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// TemplateAPIVersion is the version of the template format written by ExportDashboards.
+const TemplateAPIVersion = "influxdata.com/v2alpha1"
+
+// TemplateKind is the kind of every template.
+const TemplateKind = "Template"
+
+// ops for template service.
+const (
+	OpExportDashboards = "ExportDashboards"
+	OpApplyTemplate    = "ApplyTemplate"
+	OpRemoveStack      = "RemoveStack"
+)
+
+// TemplateService exports resources as portable templates and applies them to organizations.
+type TemplateService interface {
+	// ExportDashboards returns a template containing the dashboards of an organization
+	// and the variables their queries use.
+	ExportDashboards(ctx context.Context, orgID ID, dashboardIDs []ID) (*Template, error)
+
+	// ApplyTemplate creates or updates the resources of t in an organization and
+	// returns the stack tracking them. Applying the same template again updates
+	// the resources it created before instead of duplicating them.
+	ApplyTemplate(ctx context.Context, orgID ID, t *Template, opts TemplateApplyOptions) (*Stack, error)
+
+	// RemoveStack deletes every resource created by a stack along with the stack itself.
+	RemoveStack(ctx context.Context, id ID) error
+}
+
+// TemplateApplyOptions modify how a template is applied.
+type TemplateApplyOptions struct {
+	// StackID is the stack to update. If it is not set, the stack of the
+	// organization with the same name as the template is updated, or a new one is created.
+	StackID *ID
+	// Buckets maps the buckets referenced by the template to buckets of the organization.
+	// Buckets that are not mapped are referenced by the same name.
+	Buckets map[string]string
+}
+
+// Template is a portable description of a set of resources.
+type Template struct {
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Meta       TemplateMeta `json:"meta"`
+	Spec       TemplateSpec `json:"spec"`
+}
+
+// TemplateMeta describes a template.
+type TemplateMeta struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// TemplateSpec contains the resources of a template.
+type TemplateSpec struct {
+	// Buckets are the names of the buckets referenced by the queries of the template.
+	// They act as placeholders that are mapped to buckets of the organization the template is applied to.
+	Buckets    []string            `json:"buckets,omitempty"`
+	Variables  []TemplateVariable  `json:"variables,omitempty"`
+	Dashboards []TemplateDashboard `json:"dashboards,omitempty"`
+}
+
+// TemplateVariable is a variable in a template.
+type TemplateVariable struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Selected    []string           `json:"selected,omitempty"`
+	Arguments   *VariableArguments `json:"arguments"`
+}
+
+// TemplateDashboard is a dashboard in a template.
+type TemplateDashboard struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Cells       []TemplateCell `json:"cells,omitempty"`
+}
+
+// TemplateCell is a dashboard cell and its view in a template.
+type TemplateCell struct {
+	CellProperty
+	View *View `json:"view,omitempty"`
+}
+
+// Valid returns an error if the template cannot be applied.
+func (t *Template) Valid() error {
+	if t.Kind != TemplateKind {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "template kind must be " + TemplateKind,
+		}
+	}
+	if t.Meta.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "template must have a name",
+		}
+	}
+
+	variables := make(map[string]bool, len(t.Spec.Variables))
+	for _, v := range t.Spec.Variables {
+		if v.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "template variables must have a name",
+			}
+		}
+		if variables[v.Name] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "template contains more than one variable named " + v.Name,
+			}
+		}
+		variables[v.Name] = true
+	}
+
+	dashboards := make(map[string]bool, len(t.Spec.Dashboards))
+	for _, d := range t.Spec.Dashboards {
+		if d.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "template dashboards must have a name",
+			}
+		}
+		if dashboards[d.Name] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "template contains more than one dashboard named " + d.Name,
+			}
+		}
+		dashboards[d.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+// Package template exports dashboards as portable templates and applies
+// templates to organizations, tracking the resources they create as stacks.
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TemplateService = (*Service)(nil)
+
+// Service implements influxdb.TemplateService on top of the services of the resources in templates.
+type Service struct {
+	DashboardService influxdb.DashboardService
+	VariableService  influxdb.VariableService
+	StackService     influxdb.StackService
+}
+
+// NewService returns a new template service.
+func NewService(ds influxdb.DashboardService, vs influxdb.VariableService, ss influxdb.StackService) *Service {
+	return &Service{
+		DashboardService: ds,
+		VariableService:  vs,
+		StackService:     ss,
+	}
+}
+
+var (
+	// bucketRefPattern matches bucket references of Flux queries, as in from(bucket: "name").
+	bucketRefPattern = regexp.MustCompile(`(bucket\s*:\s*)"((?:[^"\\]|\\.)*)"`)
+	// variableRefPattern matches variable references of Flux queries, as in v.name.
+	variableRefPattern = regexp.MustCompile(`\bv\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// ExportDashboards returns a template with the dashboards of an organization and the variables their queries use.
+// If dashboardIDs is empty, every dashboard of the organization is exported.
+func (s *Service) ExportDashboards(ctx context.Context, orgID influxdb.ID, dashboardIDs []influxdb.ID) (*influxdb.Template, error) {
+	var dashboards []*influxdb.Dashboard
+	if len(dashboardIDs) == 0 {
+		ds, _, err := s.DashboardService.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &orgID}, influxdb.DefaultDashboardFindOptions)
+		if err != nil {
+			return nil, err
+		}
+		dashboards = ds
+	}
+	for _, id := range dashboardIDs {
+		d, err := s.DashboardService.FindDashboardByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if d.OrganizationID != orgID {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpExportDashboards,
+				Msg:  influxdb.ErrDashboardNotFound,
+			}
+		}
+		dashboards = append(dashboards, d)
+	}
+
+	t := &influxdb.Template{
+		APIVersion: influxdb.TemplateAPIVersion,
+		Kind:       influxdb.TemplateKind,
+	}
+	if len(dashboards) == 1 {
+		t.Meta.Name = dashboards[0].Name
+	}
+
+	buckets := make(map[string]bool)
+	variables := make(map[string]bool)
+	for _, d := range dashboards {
+		td := influxdb.TemplateDashboard{
+			Name:        d.Name,
+			Description: d.Description,
+		}
+		for _, c := range d.Cells {
+			v, err := s.DashboardService.GetDashboardCellView(ctx, d.ID, c.ID)
+			if err != nil {
+				return nil, err
+			}
+			v.ID = 0
+
+			queries, err := viewQueries(v)
+			if err != nil {
+				return nil, err
+			}
+			for _, q := range queries {
+				for _, m := range bucketRefPattern.FindAllStringSubmatch(q.Text, -1) {
+					buckets[m[2]] = true
+				}
+				for _, b := range q.BuilderConfig.Buckets {
+					buckets[b] = true
+				}
+				for _, m := range variableRefPattern.FindAllStringSubmatch(q.Text, -1) {
+					variables[m[1]] = true
+				}
+			}
+
+			td.Cells = append(td.Cells, influxdb.TemplateCell{
+				CellProperty: c.CellProperty,
+				View:         v,
+			})
+		}
+		t.Spec.Dashboards = append(t.Spec.Dashboards, td)
+	}
+
+	for b := range buckets {
+		t.Spec.Buckets = append(t.Spec.Buckets, b)
+	}
+	sort.Strings(t.Spec.Buckets)
+
+	if len(variables) > 0 {
+		vs, err := s.VariableService.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &orgID})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vs {
+			if !variables[v.Name] {
+				continue
+			}
+			t.Spec.Variables = append(t.Spec.Variables, influxdb.TemplateVariable{
+				Name:        v.Name,
+				Description: v.Description,
+				Selected:    v.Selected,
+				Arguments:   v.Arguments,
+			})
+		}
+		sort.Slice(t.Spec.Variables, func(i, j int) bool {
+			return t.Spec.Variables[i].Name < t.Spec.Variables[j].Name
+		})
+	}
+
+	return t, nil
+}
+
+// ApplyTemplate creates or updates the resources of t in an organization.
+// Resources created by a previous application of the template that are no longer
+// part of it are removed. If applying a resource fails, the stack still records
+// every resource created so far so that it can be removed.
+func (s *Service) ApplyTemplate(ctx context.Context, orgID influxdb.ID, t *influxdb.Template, opts influxdb.TemplateApplyOptions) (*influxdb.Stack, error) {
+	if err := t.Valid(); err != nil {
+		return nil, err
+	}
+
+	st, err := s.findStack(ctx, orgID, t.Meta.Name, opts.StackID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		st = &influxdb.Stack{
+			OrganizationID: orgID,
+			Name:           t.Meta.Name,
+			Description:    t.Meta.Description,
+		}
+		if err := s.StackService.CreateStack(ctx, st); err != nil {
+			return nil, err
+		}
+	}
+
+	prev := st.Resources
+	st.Description = t.Meta.Description
+	st.Resources = nil
+	applyErr := s.apply(ctx, st, prev, t, opts)
+	if applyErr == nil {
+		applyErr = s.removeStale(ctx, prev, st.Resources)
+	} else {
+		// Keep tracking the resources that were not reapplied so that they can still be removed.
+		for _, r := range prev {
+			if _, ok := st.Resource(r.Type, r.Name); !ok {
+				st.Resources = append(st.Resources, r)
+			}
+		}
+	}
+
+	if err := s.StackService.ReplaceStack(ctx, st); err != nil {
+		return nil, err
+	}
+	if applyErr != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpApplyTemplate,
+			Err: applyErr,
+		}
+	}
+	return st, nil
+}
+
+// findStack returns the stack to update when applying a template, or nil if a new stack should be created.
+func (s *Service) findStack(ctx context.Context, orgID influxdb.ID, name string, id *influxdb.ID) (*influxdb.Stack, error) {
+	if id != nil {
+		st, err := s.StackService.FindStackByID(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+		if st.OrganizationID != orgID {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpApplyTemplate,
+				Msg:  influxdb.ErrStackNotFound,
+			}
+		}
+		return st, nil
+	}
+
+	stacks, err := s.StackService.FindStacks(ctx, influxdb.StackFilter{
+		OrganizationID: &orgID,
+		Name:           &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stacks) == 0 {
+		return nil, nil
+	}
+	return stacks[0], nil
+}
+
+func (s *Service) apply(ctx context.Context, st *influxdb.Stack, prev []influxdb.StackResource, t *influxdb.Template, opts influxdb.TemplateApplyOptions) error {
+	existing := &influxdb.Stack{Resources: prev}
+
+	for _, tv := range t.Spec.Variables {
+		if tv.Arguments == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "variable " + tv.Name + " has no arguments",
+			}
+		}
+		v := &influxdb.Variable{
+			OrganizationID: st.OrganizationID,
+			Name:           tv.Name,
+			Description:    tv.Description,
+			Selected:       tv.Selected,
+			Arguments:      tv.Arguments,
+		}
+		if err := v.Valid(); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid variable " + tv.Name,
+				Err:  err,
+			}
+		}
+
+		if r, ok := existing.Resource(influxdb.VariablesResourceType, tv.Name); ok {
+			v.ID = r.ID
+			if err := s.VariableService.ReplaceVariable(ctx, v); err != nil {
+				return err
+			}
+		} else if err := s.VariableService.CreateVariable(ctx, v); err != nil {
+			return err
+		}
+		st.Resources = append(st.Resources, influxdb.StackResource{
+			Type: influxdb.VariablesResourceType,
+			ID:   v.ID,
+			Name: tv.Name,
+		})
+	}
+
+	for _, td := range t.Spec.Dashboards {
+		cells := make([]*influxdb.Cell, 0, len(td.Cells))
+		views := make([]*influxdb.View, 0, len(td.Cells))
+		for _, tc := range td.Cells {
+			v := &influxdb.View{Properties: influxdb.EmptyViewProperties{}}
+			if tc.View != nil {
+				mapped, err := mapViewBuckets(tc.View, opts.Buckets)
+				if err != nil {
+					return err
+				}
+				v = mapped
+			}
+			cells = append(cells, &influxdb.Cell{CellProperty: tc.CellProperty})
+			views = append(views, v)
+		}
+
+		var d *influxdb.Dashboard
+		if r, ok := existing.Resource(influxdb.DashboardsResourceType, td.Name); ok {
+			upd := influxdb.DashboardUpdate{
+				Name:        &td.Name,
+				Description: &td.Description,
+			}
+			var err error
+			if d, err = s.DashboardService.UpdateDashboard(ctx, r.ID, upd); err != nil {
+				return err
+			}
+			// Cells are recreated so that their views match the template.
+			for _, c := range d.Cells {
+				if err := s.DashboardService.RemoveDashboardCell(ctx, d.ID, c.ID); err != nil {
+					return err
+				}
+			}
+		} else {
+			d = &influxdb.Dashboard{
+				OrganizationID: st.OrganizationID,
+				Name:           td.Name,
+				Description:    td.Description,
+			}
+			if err := s.DashboardService.CreateDashboard(ctx, d); err != nil {
+				return err
+			}
+		}
+		st.Resources = append(st.Resources, influxdb.StackResource{
+			Type: influxdb.DashboardsResourceType,
+			ID:   d.ID,
+			Name: td.Name,
+		})
+
+		for i, c := range cells {
+			if err := s.DashboardService.AddDashboardCell(ctx, d.ID, c, influxdb.AddDashboardCellOptions{View: views[i]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeStale deletes the resources of prev that are not in cur.
+func (s *Service) removeStale(ctx context.Context, prev, cur []influxdb.StackResource) error {
+	current := &influxdb.Stack{Resources: cur}
+	var stale []influxdb.StackResource
+	for _, r := range prev {
+		if _, ok := current.Resource(r.Type, r.Name); !ok {
+			stale = append(stale, r)
+		}
+	}
+	return s.deleteResources(ctx, stale)
+}
+
+// RemoveStack deletes every resource created by a stack along with the stack itself.
+func (s *Service) RemoveStack(ctx context.Context, id influxdb.ID) error {
+	st, err := s.StackService.FindStackByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteResources(ctx, st.Resources); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRemoveStack,
+			Err: err,
+		}
+	}
+	return s.StackService.DeleteStack(ctx, id)
+}
+
+// deleteResources deletes resources, ignoring the ones that were already deleted.
+func (s *Service) deleteResources(ctx context.Context, rs []influxdb.StackResource) error {
+	for _, r := range rs {
+		var err error
+		switch r.Type {
+		case influxdb.DashboardsResourceType:
+			err = s.DashboardService.DeleteDashboard(ctx, r.ID)
+		case influxdb.VariablesResourceType:
+			err = s.VariableService.DeleteVariable(ctx, r.ID)
+		default:
+			err = &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "stack contains a resource of unknown type " + string(r.Type),
+			}
+		}
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewQueries returns the queries of a view, whatever the type of its properties.
+func viewQueries(v *influxdb.View) ([]influxdb.DashboardQuery, error) {
+	b, err := influxdb.MarshalViewPropertiesJSON(v.Properties)
+	if err != nil {
+		return nil, err
+	}
+	var props struct {
+		Queries []influxdb.DashboardQuery `json:"queries"`
+	}
+	if err := json.Unmarshal(b, &props); err != nil {
+		return nil, err
+	}
+	return props.Queries, nil
+}
+
+// mapViewBuckets returns a copy of v with the buckets referenced by its queries renamed according to buckets.
+func mapViewBuckets(v *influxdb.View, buckets map[string]string) (*influxdb.View, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 {
+		mapped := &influxdb.View{}
+		if err := json.Unmarshal(b, mapped); err != nil {
+			return nil, err
+		}
+		return mapped, nil
+	}
+
+	// The queries are rewritten generically so that every type of view properties is supported.
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if props, ok := raw["properties"].(map[string]interface{}); ok {
+		queries, _ := props["queries"].([]interface{})
+		for _, q := range queries {
+			q, ok := q.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if text, ok := q["text"].(string); ok {
+				q["text"] = mapQueryBuckets(text, buckets)
+			}
+			if bc, ok := q["builderConfig"].(map[string]interface{}); ok {
+				bs, _ := bc["buckets"].([]interface{})
+				for i, name := range bs {
+					if name, ok := name.(string); ok {
+						if to, ok := buckets[name]; ok {
+							bs[i] = to
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if b, err = json.Marshal(raw); err != nil {
+		return nil, err
+	}
+	mapped := &influxdb.View{}
+	if err := json.Unmarshal(b, mapped); err != nil {
+		return nil, err
+	}
+	return mapped, nil
+}
+
+// mapQueryBuckets renames the buckets referenced by a Flux query.
+func mapQueryBuckets(text string, buckets map[string]string) string {
+	return bucketRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		m := bucketRefPattern.FindStringSubmatch(ref)
+		to, ok := buckets[m[2]]
+		if !ok {
+			return ref
+		}
+		b, _ := json.Marshal(to)
+		return m[1] + string(b)
+	})
+}
@@ -0,0 +1,173 @@
+package template_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/template"
+)
+
+func newKVService(t *testing.T) *kv.Service {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestService_ExportApply(t *testing.T) {
+	ctx := context.Background()
+	svc := newKVService(t)
+	ts := template.NewService(svc, svc, svc)
+
+	var (
+		src = influxdb.ID(10)
+		dst = influxdb.ID(20)
+	)
+
+	host := &influxdb.Variable{
+		OrganizationID: src,
+		Name:           "host",
+		Arguments: &influxdb.VariableArguments{
+			Type:   "constant",
+			Values: influxdb.VariableConstantValues{"a", "b"},
+		},
+	}
+	unused := &influxdb.Variable{
+		OrganizationID: src,
+		Name:           "unused",
+		Arguments: &influxdb.VariableArguments{
+			Type:   "constant",
+			Values: influxdb.VariableConstantValues{"c"},
+		},
+	}
+	for _, v := range []*influxdb.Variable{host, unused} {
+		if err := svc.CreateVariable(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &influxdb.Dashboard{OrganizationID: src, Name: "hosts"}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	cell := &influxdb.Cell{CellProperty: influxdb.CellProperty{X: 1, Y: 2, W: 3, H: 4}}
+	view := &influxdb.View{
+		ViewContents: influxdb.ViewContents{Name: "cpu"},
+		Properties: influxdb.XYViewProperties{
+			Type: "xy",
+			Queries: []influxdb.DashboardQuery{{
+				Text: `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r.host == v.host)`,
+			}},
+		},
+	}
+	if err := svc.AddDashboardCell(ctx, d.ID, cell, influxdb.AddDashboardCellOptions{View: view}); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := ts.ExportDashboards(ctx, src, []influxdb.ID{d.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Meta.Name != "hosts" {
+		t.Errorf("expected template to be named after the dashboard, got %q", tmpl.Meta.Name)
+	}
+	if exp := []string{"telegraf"}; !reflect.DeepEqual(tmpl.Spec.Buckets, exp) {
+		t.Errorf("expected buckets %v, got %v", exp, tmpl.Spec.Buckets)
+	}
+	if len(tmpl.Spec.Variables) != 1 || tmpl.Spec.Variables[0].Name != "host" {
+		t.Fatalf("expected only the host variable to be exported, got %+v", tmpl.Spec.Variables)
+	}
+	if len(tmpl.Spec.Dashboards) != 1 || len(tmpl.Spec.Dashboards[0].Cells) != 1 {
+		t.Fatalf("expected one dashboard with one cell, got %+v", tmpl.Spec.Dashboards)
+	}
+
+	opts := influxdb.TemplateApplyOptions{Buckets: map[string]string{"telegraf": "metrics"}}
+	st, err := ts.ApplyTemplate(ctx, dst, tmpl, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Resources) != 2 {
+		t.Fatalf("expected stack to track 2 resources, got %+v", st.Resources)
+	}
+
+	r, ok := st.Resource(influxdb.DashboardsResourceType, "hosts")
+	if !ok {
+		t.Fatal("expected stack to track the dashboard")
+	}
+	applied, err := svc.FindDashboardByID(ctx, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.OrganizationID != dst || len(applied.Cells) != 1 || applied.Cells[0].CellProperty != cell.CellProperty {
+		t.Fatalf("unexpected applied dashboard %+v", applied)
+	}
+	v, err := svc.GetDashboardCellView(ctx, applied.ID, applied.Cells[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `from(bucket: "metrics") |> range(start: -1h) |> filter(fn: (r) => r.host == v.host)`
+	if got := v.Properties.(influxdb.XYViewProperties).Queries[0].Text; got != exp {
+		t.Errorf("expected bucket to be mapped:\nexp: %s\ngot: %s", exp, got)
+	}
+
+	// Applying the template again updates the resources in place.
+	again, err := ts.ApplyTemplate(ctx, dst, tmpl, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != st.ID || !reflect.DeepEqual(again.Resources, st.Resources) {
+		t.Fatalf("expected reapplying to update stack %+v, got %+v", st, again)
+	}
+	dashboards, _, err := svc.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &dst}, influxdb.DefaultDashboardFindOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dashboards) != 1 || len(dashboards[0].Cells) != 1 {
+		t.Fatalf("expected reapplying not to duplicate dashboards or cells, got %+v", dashboards)
+	}
+
+	// Resources removed from the template are removed from the organization.
+	tmpl.Spec.Variables = nil
+	st, err = ts.ApplyTemplate(ctx, dst, tmpl, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	variables, err := svc.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &dst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variables) != 0 || len(st.Resources) != 1 {
+		t.Fatalf("expected variable to be removed, got %+v and stack %+v", variables, st.Resources)
+	}
+
+	if err := ts.RemoveStack(ctx, st.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindDashboardByID(ctx, r.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected dashboard to be removed with the stack, got %v", err)
+	}
+	if _, err := svc.FindStackByID(ctx, st.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected stack to be removed, got %v", err)
+	}
+
+	// The source organization is untouched.
+	if _, err := svc.FindDashboardByID(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_ApplyInvalid(t *testing.T) {
+	svc := newKVService(t)
+	ts := template.NewService(svc, svc, svc)
+
+	_, err := ts.ApplyTemplate(context.Background(), 10, &influxdb.Template{Kind: "Dashboard"}, influxdb.TemplateApplyOptions{})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid template error, got %v", err)
+	}
+}